@@ -179,12 +179,14 @@ func (kc *LkkConvert) Str2Bool(val string) (res bool) {
 // 转换之后若没做其他操作直接改变里面的字符,则程序会崩溃.
 // 如 b:=String2bytes("xxx"); b[1]='d'; 程序将panic.
 func (kc *LkkConvert) Str2Bytes(val string) []byte {
-	pSliceHeader := &reflect.SliceHeader{}
+	// 经由指向真实切片的SliceHeader设置字段,使数据指针始终被GC追踪
+	var res []byte
+	pSliceHeader := (*reflect.SliceHeader)(unsafe.Pointer(&res))
 	strHeader := (*reflect.StringHeader)(unsafe.Pointer(&val))
 	pSliceHeader.Data = strHeader.Data
 	pSliceHeader.Len = strHeader.Len
 	pSliceHeader.Cap = strHeader.Len
-	return *(*[]byte)(unsafe.Pointer(pSliceHeader))
+	return res
 }
 
 // Bytes2Str 将字节切片转换为字符串.
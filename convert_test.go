@@ -377,6 +377,14 @@ func TestStr2Bytes(t *testing.T) {
 		t.Error("Str2Bytes fail")
 		return
 	}
+	if string(res) != str || len(res) != len(str) || cap(res) != len(str) {
+		t.Error("Str2Bytes fail")
+		return
+	}
+	if res = KConv.Str2Bytes(""); len(res) != 0 {
+		t.Error("Str2Bytes fail")
+		return
+	}
 }
 
 func BenchmarkStr2Bytes(b *testing.B) {
@@ -1,26 +1,43 @@
 package kgo
 
 import (
+	"bufio"
 	"bytes"
 	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"crypto/x509"
+	"encoding/ascii85"
+	"encoding/asn1"
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
 	"hash"
 	"io"
+	"math"
 	"math/big"
+	"math/bits"
 	"strconv"
 	"strings"
 	"time"
@@ -64,6 +81,235 @@ func (ke *LkkEncrypt) Base64UrlDecode(data string) ([]byte, error) {
 	return base64.URLEncoding.DecodeString(data)
 }
 
+// base58Alphabet 返回可选参数中的Base58字母表,默认BASE58_BITCOIN;字母表须为58个互不重复的ASCII字符.
+func base58Alphabet(alphabet []string, fn string) (string, error) {
+	if len(alphabet) == 0 {
+		return BASE58_BITCOIN, nil
+	}
+
+	abc := alphabet[0]
+	if len(abc) != 58 {
+		return "", fmt.Errorf("[%s] invalid alphabet length %d, must be 58", fn, len(abc))
+	}
+
+	var seen [256]bool
+	for i := 0; i < len(abc); i++ {
+		if abc[i] >= 0x80 {
+			return "", fmt.Errorf("[%s] invalid alphabet: non-ASCII character at offset %d", fn, i)
+		} else if seen[abc[i]] {
+			return "", fmt.Errorf("[%s] invalid alphabet: duplicate character %q", fn, abc[i])
+		}
+		seen[abc[i]] = true
+	}
+
+	return abc, nil
+}
+
+// Base58Encode 使用Base58对数据进行编码;alphabet为字母表,默认BASE58_BITCOIN,可选BASE58_FLICKR.
+// 字母表长度不为58或含重复字符时返回错误.
+func (ke *LkkEncrypt) Base58Encode(data []byte, alphabet ...string) (string, error) {
+	abc, err := base58Alphabet(alphabet, "Base58Encode")
+	if err != nil {
+		return "", err
+	}
+	return baseXEncode(data, abc), nil
+}
+
+// Base58Decode 对Base58编码的数据进行解码;alphabet为字母表,默认BASE58_BITCOIN,可选BASE58_FLICKR.
+// 字母表长度不为58或含重复字符时返回错误.
+func (ke *LkkEncrypt) Base58Decode(str string, alphabet ...string) ([]byte, error) {
+	abc, err := base58Alphabet(alphabet, "Base58Decode")
+	if err != nil {
+		return nil, err
+	}
+	return baseXDecode(str, abc, "Base58Decode")
+}
+
+// Base58CheckEncode 使用Base58Check编码,即version+payload+前4字节的双重SHA256校验和,再进行比特币Base58编码.
+func (ke *LkkEncrypt) Base58CheckEncode(version byte, payload []byte) string {
+	data := make([]byte, 0, len(payload)+5)
+	data = append(data, version)
+	data = append(data, payload...)
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return baseXEncode(append(data, second[:4]...), BASE58_BITCOIN)
+}
+
+// Base58CheckDecode 对Base58Check编码的数据进行解码并验证校验和,返回版本号和载荷.
+func (ke *LkkEncrypt) Base58CheckDecode(str string) (version byte, payload []byte, err error) {
+	data, err := baseXDecode(str, BASE58_BITCOIN, "Base58CheckDecode")
+	if err != nil {
+		return
+	} else if len(data) < 5 {
+		err = fmt.Errorf("[Base58CheckDecode] data too short: %d bytes", len(data))
+		return
+	}
+
+	first := sha256.Sum256(data[:len(data)-4])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], data[len(data)-4:]) {
+		err = ErrBase58Checksum
+		return
+	}
+
+	return data[0], data[1 : len(data)-4], nil
+}
+
+// Base32Encode 使用RFC 4648 Base32对数据进行编码;hexAlphabet为true时使用扩展十六进制字母表(base32hex).
+func (ke *LkkEncrypt) Base32Encode(data []byte, hexAlphabet bool) string {
+	if hexAlphabet {
+		return base32.HexEncoding.EncodeToString(data)
+	}
+	return base32.StdEncoding.EncodeToString(data)
+}
+
+// Base32Decode 对RFC 4648 Base32编码的数据进行解码,可省略结尾的'=';hexAlphabet为true时使用扩展十六进制字母表.
+func (ke *LkkEncrypt) Base32Decode(str string, hexAlphabet bool) ([]byte, error) {
+	if missing := len(str) % 8; missing != 0 {
+		str += strings.Repeat("=", 8-missing)
+	}
+	if hexAlphabet {
+		return base32.HexEncoding.DecodeString(str)
+	}
+	return base32.StdEncoding.DecodeString(str)
+}
+
+// Base32CrockfordEncode 使用Crockford Base32对数据进行编码,无填充.
+func (ke *LkkEncrypt) Base32CrockfordEncode(data []byte) string {
+	return base32.NewEncoding(BASE32_CROCKFORD).WithPadding(base32.NoPadding).EncodeToString(data)
+}
+
+// Base32CrockfordDecode 对Crockford Base32编码的数据进行解码;不区分大小写,忽略'-',I/L视为1,O视为0.
+func (ke *LkkEncrypt) Base32CrockfordDecode(str string) ([]byte, error) {
+	buf := make([]byte, 0, len(str))
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		switch c {
+		case '-':
+			continue
+		case 'I', 'L':
+			c = '1'
+		case 'O':
+			c = '0'
+		}
+		if strings.IndexByte(BASE32_CROCKFORD, c) < 0 {
+			return nil, fmt.Errorf("[Base32CrockfordDecode] invalid character %q at offset %d", str[i], i)
+		}
+		buf = append(buf, c)
+	}
+
+	return base32.NewEncoding(BASE32_CROCKFORD).WithPadding(base32.NoPadding).DecodeString(string(buf))
+}
+
+// Base62Encode 使用Base62(0-9A-Za-z)对数据进行编码.
+func (ke *LkkEncrypt) Base62Encode(data []byte) string {
+	return baseXEncode(data, BASE62_ALPHABET)
+}
+
+// Base62Decode 对Base62编码的数据进行解码.
+func (ke *LkkEncrypt) Base62Decode(str string) ([]byte, error) {
+	return baseXDecode(str, BASE62_ALPHABET, "Base62Decode")
+}
+
+// Base62EncodeUint 将整数编码为Base62字符串,适用于生成短ID.
+func (ke *LkkEncrypt) Base62EncodeUint(num uint64) string {
+	if num == 0 {
+		return BASE62_ALPHABET[:1]
+	}
+
+	var res []byte
+	for num > 0 {
+		res = append([]byte{BASE62_ALPHABET[num%62]}, res...)
+		num /= 62
+	}
+	return string(res)
+}
+
+// Base62DecodeUint 将Base62字符串解码为整数.
+func (ke *LkkEncrypt) Base62DecodeUint(str string) (uint64, error) {
+	if str == "" {
+		return 0, fmt.Errorf("[Base62DecodeUint] empty string")
+	}
+
+	var res uint64
+	for i := 0; i < len(str); i++ {
+		pos := strings.IndexByte(BASE62_ALPHABET, str[i])
+		if pos < 0 {
+			return 0, fmt.Errorf("[Base62DecodeUint] invalid character %q at offset %d", str[i], i)
+		} else if res > (math.MaxUint64-uint64(pos))/62 {
+			return 0, fmt.Errorf("[Base62DecodeUint] %q overflows uint64", str)
+		}
+		res = res*62 + uint64(pos)
+	}
+	return res, nil
+}
+
+// Ascii85Encode 使用Ascii85(btoa)对数据进行编码,不含<~和~>定界符.
+func (ke *LkkEncrypt) Ascii85Encode(data []byte) string {
+	buf := make([]byte, ascii85.MaxEncodedLen(len(data)))
+	n := ascii85.Encode(buf, data)
+	return string(buf[:n])
+}
+
+// Ascii85Decode 对Ascii85编码的数据进行解码,会去除<~和~>定界符,忽略空白.
+func (ke *LkkEncrypt) Ascii85Decode(str string) ([]byte, error) {
+	str = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(str), "<~"), "~>")
+	buf := make([]byte, 4*len(str))
+	n, _, err := ascii85.Decode(buf, []byte(str), true)
+	if err != nil {
+		return nil, fmt.Errorf("[Ascii85Decode] %s", err.Error())
+	}
+	return buf[:n], nil
+}
+
+// Z85Encode 使用ZeroMQ Z85对数据进行编码,数据长度须为4的倍数.
+func (ke *LkkEncrypt) Z85Encode(data []byte) (string, error) {
+	if len(data)%4 != 0 {
+		return "", fmt.Errorf("[Z85Encode] data length %d is not a multiple of 4", len(data))
+	}
+
+	res := make([]byte, 0, len(data)/4*5)
+	for i := 0; i < len(data); i += 4 {
+		val := binary.BigEndian.Uint32(data[i:])
+		var chunk [5]byte
+		for j := 4; j >= 0; j-- {
+			chunk[j] = Z85_ALPHABET[val%85]
+			val /= 85
+		}
+		res = append(res, chunk[:]...)
+	}
+	return string(res), nil
+}
+
+// Z85Decode 对Z85编码的数据进行解码,字符串长度须为5的倍数.
+func (ke *LkkEncrypt) Z85Decode(str string) ([]byte, error) {
+	if len(str)%5 != 0 {
+		return nil, fmt.Errorf("[Z85Decode] string length %d is not a multiple of 5", len(str))
+	}
+
+	res := make([]byte, 0, len(str)/5*4)
+	for i := 0; i < len(str); i += 5 {
+		var val uint64
+		for j := 0; j < 5; j++ {
+			pos := strings.IndexByte(Z85_ALPHABET, str[i+j])
+			if pos < 0 {
+				return nil, fmt.Errorf("[Z85Decode] invalid character %q at offset %d", str[i+j], i+j)
+			}
+			val = val*85 + uint64(pos)
+		}
+		if val > math.MaxUint32 {
+			return nil, fmt.Errorf("[Z85Decode] invalid block %q at offset %d", str[i:i+5], i)
+		}
+		var chunk [4]byte
+		binary.BigEndian.PutUint32(chunk[:], uint32(val))
+		res = append(res, chunk[:]...)
+	}
+	return res, nil
+}
+
 // AuthCode 授权码编码或解码;encode为true时编码,为false解码;expiry为有效期,秒;返回结果为加密/解密的字符串和有效期时间戳.
 func (ke *LkkEncrypt) AuthCode(str, key string, encode bool, expiry int64) (string, int64) {
 	// DYNAMIC_KEY_LEN 动态密钥长度，相同的明文会生成不同密文就是依靠动态密钥
@@ -183,10 +429,237 @@ func (ke *LkkEncrypt) PasswordHash(password []byte, costs ...int) ([]byte, error
 	return bytes, err
 }
 
-// PasswordVerify 验证密码是否和散列值匹配.
+// PasswordVerify 验证密码是否和散列值匹配,根据散列值的前缀自动识别bcrypt/argon2id/scrypt/pbkdf2算法.
 func (ke *LkkEncrypt) PasswordVerify(password, hash []byte) bool {
-	err := bcrypt.CompareHashAndPassword(hash, password)
-	return err == nil
+	policy, salt, key, err := parsePasswordHash(hash)
+	if err != nil {
+		return false
+	} else if policy.Algo == PASSWORD_BCRYPT {
+		return bcrypt.CompareHashAndPassword(hash, password) == nil
+	}
+
+	res, err := derivePasswordKey(password, salt, policy)
+	return err == nil && subtle.ConstantTimeCompare(res, key) == 1
+}
+
+// normalize 返回只保留算法相关参数并补全默认值的策略.
+func (p PasswordPolicy) normalize() PasswordPolicy {
+	res := PasswordPolicy{Algo: p.Algo, SaltLen: p.SaltLen, KeyLen: p.KeyLen}
+	if res.SaltLen <= 0 {
+		res.SaltLen = 16
+	}
+	if res.KeyLen <= 0 {
+		res.KeyLen = 32
+	}
+
+	switch p.Algo {
+	case PASSWORD_ARGON2ID:
+		res.Memory, res.Time, res.Threads = p.Memory, p.Time, p.Threads
+		if res.Memory == 0 {
+			res.Memory = 64 * 1024
+		}
+		if res.Time == 0 {
+			res.Time = 3
+		}
+		if res.Threads == 0 {
+			res.Threads = 4
+		}
+	case PASSWORD_SCRYPT:
+		res.N, res.R, res.P = p.N, p.R, p.P
+		if res.N <= 0 {
+			res.N = 32768
+		}
+		if res.R <= 0 {
+			res.R = 8
+		}
+		if res.P <= 0 {
+			res.P = 1
+		}
+	case PASSWORD_PBKDF2:
+		res.Iterations = p.Iterations
+		if res.Iterations <= 0 {
+			res.Iterations = 600000
+		}
+	default:
+		//bcrypt的盐和结果长度固定
+		res = PasswordPolicy{Algo: PASSWORD_BCRYPT, Cost: p.Cost}
+		if res.Cost == 0 {
+			res.Cost = 10
+		} else if res.Cost < 4 {
+			res.Cost = 4
+		} else if res.Cost > 31 {
+			res.Cost = 15
+		}
+	}
+
+	return res
+}
+
+// checkLimits 检查策略的开销参数是否超出允许的最大值.
+func (p PasswordPolicy) checkLimits() error {
+	switch p.Algo {
+	case PASSWORD_ARGON2ID:
+		if p.Memory > PASSWORD_ARGON2_MAX_MEMORY || p.Time > PASSWORD_ARGON2_MAX_TIME || p.Threads > PASSWORD_ARGON2_MAX_THREADS {
+			return fmt.Errorf("argon2id params m=%d,t=%d,p=%d exceed limits", p.Memory, p.Time, p.Threads)
+		}
+	case PASSWORD_SCRYPT:
+		if p.N > PASSWORD_SCRYPT_MAX_MEMORY/128 || p.R > PASSWORD_SCRYPT_MAX_MEMORY/128/p.N || p.P > PASSWORD_SCRYPT_MAX_P {
+			return fmt.Errorf("scrypt params N=%d,r=%d,p=%d exceed limits", p.N, p.R, p.P)
+		}
+	case PASSWORD_PBKDF2:
+		if p.Iterations > PASSWORD_PBKDF2_MAX_ITERATIONS {
+			return fmt.Errorf("pbkdf2 iterations %d exceed limits", p.Iterations)
+		}
+	}
+
+	return nil
+}
+
+// derivePasswordKey 按策略计算密码的散列结果,不支持bcrypt.
+func derivePasswordKey(password, salt []byte, policy PasswordPolicy) ([]byte, error) {
+	switch policy.Algo {
+	case PASSWORD_ARGON2ID:
+		return argon2.IDKey(password, salt, policy.Time, policy.Memory, policy.Threads, uint32(policy.KeyLen)), nil
+	case PASSWORD_SCRYPT:
+		return scrypt.Key(password, salt, policy.N, policy.R, policy.P, policy.KeyLen)
+	case PASSWORD_PBKDF2:
+		return pbkdf2.Key(password, salt, policy.Iterations, policy.KeyLen, sha256.New), nil
+	}
+
+	return nil, fmt.Errorf("unsupported password algorithm %d", policy.Algo)
+}
+
+// parsePasswordHash 解析密码散列值,返回其策略、盐和散列结果;bcrypt只返回策略.
+func parsePasswordHash(hash []byte) (policy PasswordPolicy, salt, key []byte, err error) {
+	str := string(hash)
+	if strings.HasPrefix(str, "$2") {
+		policy.Algo = PASSWORD_BCRYPT
+		policy.Cost, err = bcrypt.Cost(hash)
+		return
+	}
+
+	//PHC格式:$id[$v=version]$params$salt$hash
+	parts := strings.Split(str, "$")
+	if len(parts) == 6 && parts[1] == "argon2id" {
+		if parts[2] != "v=19" {
+			err = errors.New("[parsePasswordHash] unsupported argon2 version")
+			return
+		}
+		parts = append(parts[:2], parts[3:]...)
+	}
+	if len(parts) != 5 || parts[0] != "" {
+		err = errors.New("[parsePasswordHash] invalid password hash")
+		return
+	}
+
+	params := make(map[string]int)
+	for _, item := range strings.Split(parts[2], ",") {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+		if params[kv[0]], err = strconv.Atoi(kv[1]); err != nil || params[kv[0]] <= 0 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+	}
+
+	switch parts[1] {
+	case "argon2id":
+		policy.Algo = PASSWORD_ARGON2ID
+		if int64(params["m"]) > math.MaxUint32 || int64(params["t"]) > math.MaxUint32 || params["p"] > math.MaxUint8 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+		policy.Memory, policy.Time, policy.Threads = uint32(params["m"]), uint32(params["t"]), uint8(params["p"])
+		if policy.Memory == 0 || policy.Time == 0 || policy.Threads == 0 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+	case "scrypt":
+		policy.Algo = PASSWORD_SCRYPT
+		if params["ln"] == 0 || params["ln"] > 62 || params["r"] == 0 || params["p"] == 0 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+		policy.N, policy.R, policy.P = 1<<uint(params["ln"]), params["r"], params["p"]
+	case "pbkdf2-sha256":
+		policy.Algo = PASSWORD_PBKDF2
+		if policy.Iterations = params["i"]; policy.Iterations == 0 {
+			err = errors.New("[parsePasswordHash] invalid password hash params")
+			return
+		}
+	default:
+		err = fmt.Errorf("[parsePasswordHash] unsupported password algorithm %s", parts[1])
+		return
+	}
+
+	//散列值来自外部存储,参数过大时拒绝计算,避免耗尽内存或CPU
+	if err = policy.checkLimits(); err != nil {
+		err = fmt.Errorf("[parsePasswordHash] %s", err)
+		return
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return
+	} else if key, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return
+	} else if len(salt) == 0 || len(key) == 0 {
+		err = errors.New("[parsePasswordHash] invalid password hash")
+		return
+	}
+	policy.SaltLen, policy.KeyLen = len(salt), len(key)
+
+	return
+}
+
+// PasswordHashWith 按策略创建密码的散列值.
+// argon2id/scrypt/pbkdf2的结果为PHC字符串格式,如$argon2id$v=19$m=65536,t=3,p=4$salt$hash,可由PasswordVerify验证.
+func (ke *LkkEncrypt) PasswordHashWith(password []byte, policy PasswordPolicy) ([]byte, error) {
+	policy = policy.normalize()
+	if policy.Algo == PASSWORD_BCRYPT {
+		return bcrypt.GenerateFromPassword(password, policy.Cost)
+	}
+
+	var params string
+	switch policy.Algo {
+	case PASSWORD_ARGON2ID:
+		params = fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d", policy.Memory, policy.Time, policy.Threads)
+	case PASSWORD_SCRYPT:
+		if policy.N <= 1 || policy.N&(policy.N-1) != 0 {
+			return nil, errors.New("[PasswordHashWith] scrypt N must be a power of 2 greater than 1")
+		}
+		params = fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d", bits.TrailingZeros(uint(policy.N)), policy.R, policy.P)
+	case PASSWORD_PBKDF2:
+		params = fmt.Sprintf("$pbkdf2-sha256$i=%d", policy.Iterations)
+	}
+	if err := policy.checkLimits(); err != nil {
+		return nil, fmt.Errorf("[PasswordHashWith] %s", err)
+	}
+
+	salt := make([]byte, policy.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := derivePasswordKey(password, salt, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(params + "$" + base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// PasswordNeedsRehash 检查散列值的算法或参数是否与策略不一致,需要重新散列(如在登录验证成功后升级).
+// 无法识别的散列值也返回true.
+func (ke *LkkEncrypt) PasswordNeedsRehash(hash []byte, policy PasswordPolicy) bool {
+	cur, _, _, err := parsePasswordHash(hash)
+	if err != nil {
+		return true
+	}
+
+	return cur != policy.normalize()
 }
 
 // EasyEncrypt 简单加密.
@@ -294,6 +767,14 @@ func (ke *LkkEncrypt) aesEncrypt(clearText, key []byte, mode string, paddingType
 		return nil, err
 	}
 
+	return ke.blockEncrypt(block, clearText, mode, paddingType...)
+}
+
+// blockEncrypt 分组密码加密,CBC/CFB/CTR/OFB模式的结果以随机的初始化向量开头.
+// block为分组密码;clearText为明文;
+// mode为模式,枚举值(ECB,CBC,CFB,CTR,OFB);
+// paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) blockEncrypt(block cipher.Block, clearText []byte, mode string, paddingType ...LkkPKCSType) ([]byte, error) {
 	pt := PKCS_SEVEN
 	blockSize := block.BlockSize()
 	if len(paddingType) > 0 {
@@ -306,6 +787,16 @@ func (ke *LkkEncrypt) aesEncrypt(clearText, key []byte, mode string, paddingType
 		clearText = pkcs7Padding(clearText, blockSize, false)
 	}
 
+	if (mode == "ECB" || mode == "CBC") && len(clearText)%blockSize != 0 {
+		return nil, fmt.Errorf("[%s] clearText is not a multiple of the block size", mode)
+	} else if mode == "ECB" {
+		cipherText := make([]byte, len(clearText))
+		for i := 0; i < len(clearText); i += blockSize {
+			block.Encrypt(cipherText[i:i+blockSize], clearText[i:i+blockSize])
+		}
+		return cipherText, nil
+	}
+
 	cipherText := make([]byte, blockSize+len(clearText))
 	//初始化向量
 	iv := cipherText[:blockSize]
@@ -338,6 +829,14 @@ func (ke *LkkEncrypt) aesDecrypt(cipherText, key []byte, mode string, paddingTyp
 		return nil, err
 	}
 
+	return ke.blockDecrypt("aes", block, cipherText, mode, paddingType...)
+}
+
+// blockDecrypt 分组密码解密,CBC/CFB/CTR/OFB模式的密文须以初始化向量开头.
+// name为算法名称,用于错误信息;block为分组密码;cipherText为密文;
+// mode为模式,枚举值(ECB,CBC,CFB,CTR,OFB);
+// paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) blockDecrypt(name string, block cipher.Block, cipherText []byte, mode string, paddingType ...LkkPKCSType) ([]byte, error) {
 	pt := PKCS_SEVEN
 	if len(paddingType) > 0 {
 		pt = paddingType[0]
@@ -349,23 +848,34 @@ func (ke *LkkEncrypt) aesDecrypt(cipherText, key []byte, mode string, paddingTyp
 		return nil, errors.New("cipherText too short")
 	}
 
-	iv := cipherText[:blockSize]
-	cipherText = cipherText[blockSize:]
+	if (mode == "ECB" || mode == "CBC") && clen%blockSize != 0 {
+		return nil, fmt.Errorf("[%s] cipherText is not a multiple of the block size", mode)
+	}
 
-	switch mode {
-	case "CBC":
-		cipher.NewCBCDecrypter(block, iv).CryptBlocks(cipherText, cipherText)
-	case "CFB":
-		cipher.NewCFBDecrypter(block, iv).XORKeyStream(cipherText, cipherText)
-	case "CTR":
-		cipher.NewCTR(block, iv).XORKeyStream(cipherText, cipherText)
-	case "OFB":
-		cipher.NewOFB(block, iv).XORKeyStream(cipherText, cipherText)
+	if mode == "ECB" {
+		plain := make([]byte, clen)
+		for i := 0; i < clen; i += blockSize {
+			block.Decrypt(plain[i:i+blockSize], cipherText[i:i+blockSize])
+		}
+		cipherText = plain
+	} else {
+		iv := cipherText[:blockSize]
+		cipherText = cipherText[blockSize:]
+		switch mode {
+		case "CBC":
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(cipherText, cipherText)
+		case "CFB":
+			cipher.NewCFBDecrypter(block, iv).XORKeyStream(cipherText, cipherText)
+		case "CTR":
+			cipher.NewCTR(block, iv).XORKeyStream(cipherText, cipherText)
+		case "OFB":
+			cipher.NewOFB(block, iv).XORKeyStream(cipherText, cipherText)
+		}
 	}
 
 	clen = len(cipherText)
 	if pt != PKCS_NONE && clen > 0 && int(cipherText[clen-1]) > clen {
-		return nil, errors.New(fmt.Sprintf("aes [%s] decrypt failed", mode))
+		return nil, errors.New(fmt.Sprintf("%s [%s] decrypt failed", name, mode))
 	}
 
 	var plainText []byte
@@ -429,98 +939,419 @@ func (ke *LkkEncrypt) AesOFBDecrypt(cipherText, key []byte) ([]byte, error) {
 	return ke.aesDecrypt(cipherText, key, "OFB", PKCS_NONE)
 }
 
-// GenerateRsaKeys 生成RSA密钥对.bits为密钥位数,通常为1024或2048.
-func (ke *LkkEncrypt) GenerateRsaKeys(bits int) (private []byte, public []byte, err error) {
-	// 生成私钥文件
-	var privateKey *rsa.PrivateKey
-	privateKey, err = rsa.GenerateKey(rand.Reader, bits)
-	if err != nil {
-		return
-	}
-	derStream := x509.MarshalPKCS1PrivateKey(privateKey)
-	block := &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: derStream,
+// aeadSeal 使用AEAD加密,随机生成nonce并置于密文之前.
+func (ke *LkkEncrypt) aeadSeal(aead cipher.AEAD, clearText []byte, aad [][]byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	nonce := make([]byte, nonceSize, nonceSize+len(clearText)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
 	}
-	privateBuff := new(bytes.Buffer)
-	_ = pem.Encode(privateBuff, block)
 
-	// 生成公钥文件
-	var derPkix []byte
-	publicKey := &privateKey.PublicKey
-	derPkix, _ = x509.MarshalPKIXPublicKey(publicKey)
-	block = &pem.Block{
-		Type:  "RSA PUBLIC KEY",
-		Bytes: derPkix,
+	var ad []byte
+	if len(aad) > 0 {
+		ad = aad[0]
 	}
-	publicBuff := new(bytes.Buffer)
-	_ = pem.Encode(publicBuff, block)
-
-	private = privateBuff.Bytes()
-	public = publicBuff.Bytes()
 
-	return
+	return aead.Seal(nonce, nonce, clearText, ad), nil
 }
 
-// RsaPublicEncrypt RSA公钥加密.
-// clearText为明文,publicKey为公钥.
-func (ke *LkkEncrypt) RsaPublicEncrypt(clearText, publicKey []byte) ([]byte, error) {
-	// 解密pem格式的公钥
-	block, _ := pem.Decode(publicKey)
-	if block == nil {
-		return nil, errors.New("public key error")
+// aeadOpen 使用AEAD解密,密文须以nonce开头;认证失败时返回ErrAuthFailed.
+func (ke *LkkEncrypt) aeadOpen(aead cipher.AEAD, cipherText []byte, aad [][]byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(cipherText) < nonceSize+aead.Overhead() {
+		return nil, errors.New("cipherText too short")
 	}
 
-	// 解析公钥
-	pubInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
+	var ad []byte
+	if len(aad) > 0 {
+		ad = aad[0]
+	}
+
+	plainText, err := aead.Open(nil, cipherText[:nonceSize], cipherText[nonceSize:], ad)
 	if err != nil {
-		return nil, err
+		return nil, ErrAuthFailed
 	}
 
-	// 类型断言
-	pubKey := pubInterface.(*rsa.PublicKey)
-	//加密
-	return rsa.EncryptPKCS1v15(rand.Reader, pubKey, clearText)
+	return plainText, nil
 }
 
-// RsaPrivateDecrypt RSA私钥解密.比加密耗时.
-// cipherText为密文,privateKey为私钥.
-func (ke *LkkEncrypt) RsaPrivateDecrypt(cipherText, privateKey []byte) ([]byte, error) {
-	// 获取私钥
-	block, _ := pem.Decode(privateKey)
-	if block == nil {
-		return nil, errors.New("private key error!")
-	}
-
-	// 解析PKCS1格式的私钥
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// AesGCMEncrypt AES-GCM伽罗瓦/计数器(Galois/Counter)模式认证加密.
+// clearText为明文;key为密钥,长16/24/32;aad为可选的附加认证数据.
+// 结果为12字节随机nonce+密文+16字节认证标签.
+func (ke *LkkEncrypt) AesGCMEncrypt(clearText, key []byte, aad ...[]byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	// 解密
-	return rsa.DecryptPKCS1v15(rand.Reader, priv, cipherText)
+	aead, _ := cipher.NewGCM(block)
+	return ke.aeadSeal(aead, clearText, aad)
 }
 
-// RsaPrivateEncrypt RSA私钥加密.比解密耗时.
-// clearText为明文,privateKey为私钥.
-func (ke *LkkEncrypt) RsaPrivateEncrypt(clearText, privateKey []byte) ([]byte, error) {
-	// 获取私钥
-	block, _ := pem.Decode(privateKey)
-	if block == nil {
-		return nil, errors.New("private key error!")
-	}
-
-	// 解析PKCS1格式的私钥
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// AesGCMDecrypt AES-GCM伽罗瓦/计数器(Galois/Counter)模式认证解密.
+// cipherText为密文;key为密钥,长16/24/32;aad为加密时使用的附加认证数据.
+// 密文被篡改或aad不匹配时返回ErrAuthFailed.
+func (ke *LkkEncrypt) AesGCMDecrypt(cipherText, key []byte, aad ...[]byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	return rsa.SignPKCS1v15(nil, priv, crypto.Hash(0), clearText)
+	aead, _ := cipher.NewGCM(block)
+	return ke.aeadOpen(aead, cipherText, aad)
 }
 
-// RsaPublicDecrypt RSA公钥解密.
+// ChaCha20Poly1305Encrypt ChaCha20-Poly1305认证加密,适合无AES硬件加速的环境.
+// clearText为明文;key为密钥,长32;aad为可选的附加认证数据.
+// 结果为12字节随机nonce+密文+16字节认证标签.
+func (ke *LkkEncrypt) ChaCha20Poly1305Encrypt(clearText, key []byte, aad ...[]byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ke.aeadSeal(aead, clearText, aad)
+}
+
+// ChaCha20Poly1305Decrypt ChaCha20-Poly1305认证解密.
+// cipherText为密文;key为密钥,长32;aad为加密时使用的附加认证数据.
+// 密文被篡改或aad不匹配时返回ErrAuthFailed.
+func (ke *LkkEncrypt) ChaCha20Poly1305Decrypt(cipherText, key []byte, aad ...[]byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ke.aeadOpen(aead, cipherText, aad)
+}
+
+// newStreamAead 由密钥和盐派生子密钥,创建分块加密流使用的AEAD.
+func newStreamAead(key, salt []byte, aeadType LkkAeadType) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key is empty")
+	}
+
+	subKey := make([]byte, 32)
+	kdf := hkdf.New(sha256.New, key, salt, []byte{'k', 'g', 'o', 's', byte(aeadType)})
+	if _, err := io.ReadFull(kdf, subKey); err != nil {
+		return nil, err
+	}
+
+	switch aeadType {
+	case AEAD_AES_GCM:
+		block, _ := aes.NewCipher(subKey)
+		return cipher.NewGCM(block)
+	case AEAD_CHACHA20_POLY1305:
+		return chacha20poly1305.New(subKey)
+	default:
+		return nil, fmt.Errorf("unsupported aead type %d", aeadType)
+	}
+}
+
+// streamNonce 生成第counter块的nonce,末块的最后一字节置1,用于检测截断和乱序.
+func streamNonce(nonce []byte, counter uint64, last bool) []byte {
+	for i := range nonce {
+		nonce[i] = 0
+	}
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce
+}
+
+// NewEncryptWriter 创建分块认证加密的写入器,写入的明文加密后写到w,须调用Close写出末块(不会关闭w).
+// key为任意长度的密钥;aeadType为算法,枚举(AEAD_AES_GCM,AEAD_CHACHA20_POLY1305);chunkSize为明文块大小,<=0时为STREAM_CHUNK_SIZE.
+// 输出格式为:头部(4字节"KGOS"+1字节版本+1字节算法+4字节块大小+16字节盐)+若干密文块,头部作为每块的附加认证数据.
+func (ke *LkkEncrypt) NewEncryptWriter(w io.Writer, key []byte, aeadType LkkAeadType, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize <= 0 {
+		chunkSize = STREAM_CHUNK_SIZE
+	} else if chunkSize > STREAM_CHUNK_MAX {
+		return nil, fmt.Errorf("[NewEncryptWriter] chunkSize exceeds %d", STREAM_CHUNK_MAX)
+	}
+
+	header := make([]byte, 26)
+	copy(header, "KGOS")
+	header[4] = STREAM_VERSION
+	header[5] = byte(aeadType)
+	binary.BigEndian.PutUint32(header[6:10], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[10:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := newStreamAead(key, header[10:], aeadType)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &streamEncrypter{
+		w:         w,
+		aead:      aead,
+		header:    header,
+		nonce:     make([]byte, aead.NonceSize()),
+		buf:       make([]byte, 0, chunkSize),
+		out:       make([]byte, 0, chunkSize+aead.Overhead()),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+// flush 加密并写出缓冲区中的明文块.
+func (se *streamEncrypter) flush(last bool) error {
+	se.out = se.aead.Seal(se.out[:0], streamNonce(se.nonce, se.counter, last), se.buf, se.header)
+	if _, err := se.w.Write(se.out); err != nil {
+		return err
+	}
+	se.counter++
+	se.buf = se.buf[:0]
+
+	return nil
+}
+
+// Write 写入明文,满一块后加密写出.
+func (se *streamEncrypter) Write(p []byte) (int, error) {
+	if se.err != nil {
+		return 0, se.err
+	} else if se.closed {
+		return 0, errors.New("[streamEncrypter] write after close")
+	}
+
+	total := len(p)
+	for len(p) > 0 {
+		//缓冲区满且仍有数据时才写出,保证末块在Close时写出
+		if len(se.buf) == se.chunkSize {
+			if se.err = se.flush(false); se.err != nil {
+				return total - len(p), se.err
+			}
+		}
+
+		n := copy(se.buf[len(se.buf):se.chunkSize], p)
+		se.buf = se.buf[:len(se.buf)+n]
+		p = p[n:]
+	}
+
+	return total, nil
+}
+
+// Close 加密写出末块.
+func (se *streamEncrypter) Close() error {
+	if se.closed {
+		return se.err
+	}
+	se.closed = true
+	if se.err == nil {
+		se.err = se.flush(true)
+	}
+
+	return se.err
+}
+
+// NewDecryptReader 创建分块认证解密的读取器,从r读取NewEncryptWriter生成的密文.
+// 密文被篡改或块顺序被调换时读取返回ErrAuthFailed,被截断时返回ErrStreamTruncated.
+// 注意:读取到的明文在读到io.EOF之前尚未完整校验,不应视为可信.
+func (ke *LkkEncrypt) NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 26)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrStreamHeader
+	} else if string(header[:4]) != "KGOS" {
+		return nil, ErrStreamHeader
+	} else if header[4] != STREAM_VERSION {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrStreamHeader, header[4])
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[6:10]))
+	if chunkSize <= 0 || chunkSize > STREAM_CHUNK_MAX {
+		return nil, fmt.Errorf("%w: invalid chunk size %d", ErrStreamHeader, chunkSize)
+	}
+
+	aead, err := newStreamAead(key, header[10:], LkkAeadType(header[5]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrStreamHeader, err.Error())
+	}
+
+	return &streamDecrypter{
+		r:         br,
+		aead:      aead,
+		header:    header,
+		nonce:     make([]byte, aead.NonceSize()),
+		chunk:     make([]byte, chunkSize+aead.Overhead()),
+		plainBuf:  make([]byte, 0, chunkSize),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+// next 读取并解密下一个密文块.
+func (sd *streamDecrypter) next() error {
+	n, err := io.ReadFull(sd.r, sd.chunk)
+	last := false
+	if err == io.EOF {
+		return ErrStreamTruncated
+	} else if err == io.ErrUnexpectedEOF {
+		last = true
+	} else if err != nil {
+		return err
+	} else if _, err = sd.r.Peek(1); err == io.EOF {
+		last = true
+	}
+
+	sd.plain, err = sd.aead.Open(sd.plainBuf[:0], streamNonce(sd.nonce, sd.counter, last), sd.chunk[:n], sd.header)
+	if err != nil {
+		//按非末块能解密,说明在块边界处被截断
+		if last {
+			if _, e := sd.aead.Open(sd.plainBuf[:0], streamNonce(sd.nonce, sd.counter, false), sd.chunk[:n], sd.header); e == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return fmt.Errorf("chunk %d: %w", sd.counter, ErrAuthFailed)
+	}
+
+	sd.counter++
+	sd.done = last
+
+	return nil
+}
+
+// Read 读取解密后的明文.
+func (sd *streamDecrypter) Read(p []byte) (int, error) {
+	for len(sd.plain) == 0 {
+		if sd.err != nil {
+			return 0, sd.err
+		} else if sd.done {
+			return 0, io.EOF
+		}
+		sd.err = sd.next()
+	}
+
+	n := copy(p, sd.plain)
+	sd.plain = sd.plain[n:]
+
+	return n, nil
+}
+
+// EncryptStream 将src的数据分块认证加密后写入dst,使用AES-256-GCM和默认块大小;返回写入的明文字节数.
+// key为任意长度的密钥.
+func (ke *LkkEncrypt) EncryptStream(dst io.Writer, src io.Reader, key []byte) (int64, error) {
+	ew, err := ke.NewEncryptWriter(dst, key, AEAD_AES_GCM, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(ew, src)
+	if err != nil {
+		return n, err
+	}
+
+	return n, ew.Close()
+}
+
+// DecryptStream 将src中EncryptStream/NewEncryptWriter生成的密文解密后写入dst;返回写入的明文字节数.
+// key为加密时的密钥.
+func (ke *LkkEncrypt) DecryptStream(dst io.Writer, src io.Reader, key []byte) (int64, error) {
+	dr, err := ke.NewDecryptReader(src, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return io.Copy(dst, dr)
+}
+
+// GenerateRsaKeys 生成RSA密钥对.bits为密钥位数,通常为1024或2048.
+func (ke *LkkEncrypt) GenerateRsaKeys(bits int) (private []byte, public []byte, err error) {
+	// 生成私钥文件
+	var privateKey *rsa.PrivateKey
+	privateKey, err = rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return
+	}
+	derStream := x509.MarshalPKCS1PrivateKey(privateKey)
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: derStream,
+	}
+	privateBuff := new(bytes.Buffer)
+	_ = pem.Encode(privateBuff, block)
+
+	// 生成公钥文件
+	var derPkix []byte
+	publicKey := &privateKey.PublicKey
+	derPkix, _ = x509.MarshalPKIXPublicKey(publicKey)
+	block = &pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: derPkix,
+	}
+	publicBuff := new(bytes.Buffer)
+	_ = pem.Encode(publicBuff, block)
+
+	private = privateBuff.Bytes()
+	public = publicBuff.Bytes()
+
+	return
+}
+
+// RsaPublicEncrypt RSA公钥加密.
+// clearText为明文,publicKey为公钥.
+func (ke *LkkEncrypt) RsaPublicEncrypt(clearText, publicKey []byte) ([]byte, error) {
+	// 解密pem格式的公钥
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, errors.New("public key error")
+	}
+
+	// 解析公钥
+	pubInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言
+	pubKey := pubInterface.(*rsa.PublicKey)
+	//加密
+	return rsa.EncryptPKCS1v15(rand.Reader, pubKey, clearText)
+}
+
+// RsaPrivateDecrypt RSA私钥解密.比加密耗时.
+// cipherText为密文,privateKey为私钥.
+func (ke *LkkEncrypt) RsaPrivateDecrypt(cipherText, privateKey []byte) ([]byte, error) {
+	// 获取私钥
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("private key error!")
+	}
+
+	// 解析PKCS1格式的私钥
+	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解密
+	return rsa.DecryptPKCS1v15(rand.Reader, priv, cipherText)
+}
+
+// RsaPrivateEncrypt RSA私钥加密.比解密耗时.
+// clearText为明文,privateKey为私钥.
+func (ke *LkkEncrypt) RsaPrivateEncrypt(clearText, privateKey []byte) ([]byte, error) {
+	// 获取私钥
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("private key error!")
+	}
+
+	// 解析PKCS1格式的私钥
+	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsa.SignPKCS1v15(nil, priv, crypto.Hash(0), clearText)
+}
+
+// RsaPublicDecrypt RSA公钥解密.
 // cipherText为密文,publicKey为公钥.
 func (ke *LkkEncrypt) RsaPublicDecrypt(cipherText, publicKey []byte) ([]byte, error) {
 	// 解密pem格式的公钥
@@ -555,3 +1386,528 @@ func (ke *LkkEncrypt) RsaPublicDecrypt(cipherText, publicKey []byte) ([]byte, er
 
 	return out[skip:], nil
 }
+
+// RsaSign RSA私钥签名.
+// data为待签名数据;privateKey为pem格式的私钥(PKCS1或PKCS8);shaX为散列算法,枚举(256,384,512);
+// padding为填充方式,枚举(RSA_PADDING_PKCS1V15,RSA_PADDING_PSS),PSS的盐长度等于散列长度.
+func (ke *LkkEncrypt) RsaSign(data, privateKey []byte, shaX uint16, padding LkkRsaPadding) ([]byte, error) {
+	hashType, err := shaXHash(shaX)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := parseRsaPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hashType.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+
+	switch padding {
+	case RSA_PADDING_PKCS1V15:
+		return rsa.SignPKCS1v15(rand.Reader, priv, hashType, digest)
+	case RSA_PADDING_PSS:
+		return rsa.SignPSS(rand.Reader, priv, hashType, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	default:
+		return nil, fmt.Errorf("[RsaSign] unsupported padding %d", padding)
+	}
+}
+
+// RsaVerify RSA公钥验签,验证通过返回nil,签名不匹配返回ErrSignVerify.
+// data为原数据;sign为签名;publicKey为pem格式的公钥(PKIX或PKCS1);shaX和padding须与签名时一致.
+func (ke *LkkEncrypt) RsaVerify(data, sign, publicKey []byte, shaX uint16, padding LkkRsaPadding) error {
+	hashType, err := shaXHash(shaX)
+	if err != nil {
+		return err
+	}
+
+	pub, err := parseRsaPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	h := hashType.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+
+	switch padding {
+	case RSA_PADDING_PKCS1V15:
+		err = rsa.VerifyPKCS1v15(pub, hashType, digest, sign)
+	case RSA_PADDING_PSS:
+		err = rsa.VerifyPSS(pub, hashType, digest, sign, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	default:
+		return fmt.Errorf("[RsaVerify] unsupported padding %d", padding)
+	}
+	if err != nil {
+		return ErrSignVerify
+	}
+
+	return nil
+}
+
+// rsaOAEPLabel 返回分块加密时第i块(共n块)的OAEP标签,将各块与其位置和总块数绑定;只有一块时为空,与标准OAEP兼容.
+func rsaOAEPLabel(i, n int) []byte {
+	if n == 1 {
+		return nil
+	}
+
+	return []byte(fmt.Sprintf("kgo-oaep:%d/%d", i, n))
+}
+
+// RsaOAEPEncrypt RSA-OAEP公钥加密.明文超过单次可加密的长度时自动分块加密,密文为各块密文依次拼接.
+// 各块以OAEP标签绑定其序号和总块数,块被重排、截断或增删时解密失败;但不同消息之间同位置的块仍可互换,
+// 且只有一块时结果与标准OAEP相同.较大的数据应使用随机密钥加密(如EncryptStream),再用本方法加密该密钥.
+// clearText为明文;publicKey为pem格式的公钥;shaX为OAEP使用的散列算法,枚举(256,384,512).
+func (ke *LkkEncrypt) RsaOAEPEncrypt(clearText, publicKey []byte, shaX uint16) ([]byte, error) {
+	hashType, err := shaXHash(shaX)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := parseRsaPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	//每块可加密的最大长度
+	k := (pub.N.BitLen() + 7) / 8
+	maxLen := k - 2*hashType.Size() - 2
+	if maxLen <= 0 {
+		return nil, fmt.Errorf("[RsaOAEPEncrypt] key too short for sha%d", shaX)
+	}
+
+	total := (len(clearText) + maxLen - 1) / maxLen
+	if total == 0 {
+		total = 1
+	}
+
+	h := hashType.New()
+	res := make([]byte, 0, total*k)
+	for i := 0; ; i++ {
+		n := len(clearText)
+		if n > maxLen {
+			n = maxLen
+		}
+
+		chunk, err := rsa.EncryptOAEP(h, rand.Reader, pub, clearText[:n], rsaOAEPLabel(i, total))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chunk...)
+
+		clearText = clearText[n:]
+		if len(clearText) == 0 {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// RsaOAEPDecrypt RSA-OAEP私钥解密,支持RsaOAEPEncrypt分块加密的密文.
+// cipherText为密文;privateKey为pem格式的私钥;shaX须与加密时一致.
+func (ke *LkkEncrypt) RsaOAEPDecrypt(cipherText, privateKey []byte, shaX uint16) ([]byte, error) {
+	hashType, err := shaXHash(shaX)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := parseRsaPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	k := priv.Size()
+	if len(cipherText) == 0 || len(cipherText)%k != 0 {
+		return nil, errors.New("[RsaOAEPDecrypt] invalid cipherText length")
+	}
+
+	total := len(cipherText) / k
+	h := hashType.New()
+	res := make([]byte, 0, len(cipherText))
+	for i := 0; i < total; i++ {
+		chunk, err := rsa.DecryptOAEP(h, rand.Reader, priv, cipherText[i*k:(i+1)*k], rsaOAEPLabel(i, total))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chunk...)
+	}
+
+	return res, nil
+}
+
+// marshalPemKeys 将私钥编码为PKCS8格式,公钥编码为PKIX格式的pem.
+func marshalPemKeys(privKey, pubKey interface{}) (private []byte, public []byte, err error) {
+	var der []byte
+	if der, err = x509.MarshalPKCS8PrivateKey(privKey); err != nil {
+		return
+	}
+	private = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	if der, err = x509.MarshalPKIXPublicKey(pubKey); err != nil {
+		return
+	}
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	return
+}
+
+// GenerateEcdsaKeys 生成ECDSA密钥对,私钥为PKCS8格式,公钥为PKIX格式的pem.bits为曲线位数,枚举(256,384,521).
+func (ke *LkkEncrypt) GenerateEcdsaKeys(bits int) (private []byte, public []byte, err error) {
+	var curve elliptic.Curve
+	switch bits {
+	case 256:
+		curve = elliptic.P256()
+	case 384:
+		curve = elliptic.P384()
+	case 521:
+		curve = elliptic.P521()
+	default:
+		err = fmt.Errorf("[GenerateEcdsaKeys] unsupported curve P-%d", bits)
+		return
+	}
+
+	var privateKey *ecdsa.PrivateKey
+	if privateKey, err = ecdsa.GenerateKey(curve, rand.Reader); err != nil {
+		return
+	}
+
+	return marshalPemKeys(privateKey, &privateKey.PublicKey)
+}
+
+// GenerateEd25519Keys 生成Ed25519密钥对,私钥为PKCS8格式,公钥为PKIX格式的pem.
+func (ke *LkkEncrypt) GenerateEd25519Keys() (private []byte, public []byte, err error) {
+	var pubKey ed25519.PublicKey
+	var privKey ed25519.PrivateKey
+	if pubKey, privKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+		return
+	}
+
+	return marshalPemKeys(privKey, pubKey)
+}
+
+// ecdsaDigest 按曲线选择散列算法(P-256:SHA-256,P-384:SHA-384,P-521:SHA-512)计算摘要.
+func ecdsaDigest(curve elliptic.Curve, data []byte) []byte {
+	var h hash.Hash
+	switch curve.Params().BitSize {
+	case 384:
+		h = sha512.New384()
+	case 521:
+		h = sha512.New()
+	default:
+		h = sha256.New()
+	}
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+// EcdsaSign ECDSA私钥签名,散列算法由曲线决定(P-256:SHA-256,P-384:SHA-384,P-521:SHA-512).
+// data为待签名数据;privateKey为pem格式的私钥(PKCS8或EC);
+// raw为true时签名为定长的r||s(用于JWS),否则为ASN.1 DER编码(用于X.509/WebAuthn).
+func (ke *LkkEncrypt) EcdsaSign(data, privateKey []byte, raw bool) ([]byte, error) {
+	key, err := parsePemPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("[EcdsaSign] not a ecdsa private key")
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, priv, ecdsaDigest(priv.Curve, data))
+	if err != nil {
+		return nil, err
+	}
+
+	if raw {
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		return append(bigIntBytes(r, size), bigIntBytes(s, size)...), nil
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// EcdsaVerify ECDSA公钥验签,验证通过返回nil,签名不匹配返回ErrSignVerify.
+// data为原数据;sign为签名;publicKey为pem格式的公钥(PKIX);raw须与签名时一致.
+func (ke *LkkEncrypt) EcdsaVerify(data, sign, publicKey []byte, raw bool) error {
+	key, err := parsePemPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("[EcdsaVerify] not a ecdsa public key")
+	}
+
+	var es ecdsaSignature
+	if raw {
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sign) != 2*size {
+			return ErrSignVerify
+		}
+		es.R = new(big.Int).SetBytes(sign[:size])
+		es.S = new(big.Int).SetBytes(sign[size:])
+	} else if rest, err := asn1.Unmarshal(sign, &es); err != nil || len(rest) != 0 {
+		return ErrSignVerify
+	}
+
+	if es.R.Sign() <= 0 || es.S.Sign() <= 0 || !ecdsa.Verify(pub, ecdsaDigest(pub.Curve, data), es.R, es.S) {
+		return ErrSignVerify
+	}
+
+	return nil
+}
+
+// Ed25519Sign Ed25519私钥签名,签名为64字节.
+// data为待签名数据;privateKey为pem格式的私钥(PKCS8).
+func (ke *LkkEncrypt) Ed25519Sign(data, privateKey []byte) ([]byte, error) {
+	key, err := parsePemPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("[Ed25519Sign] not a ed25519 private key")
+	}
+
+	return ed25519.Sign(priv, data), nil
+}
+
+// Ed25519Verify Ed25519公钥验签,验证通过返回nil,签名不匹配返回ErrSignVerify.
+// data为原数据;sign为签名;publicKey为pem格式的公钥(PKIX).
+func (ke *LkkEncrypt) Ed25519Verify(data, sign, publicKey []byte) error {
+	key, err := parsePemPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("[Ed25519Verify] not a ed25519 public key")
+	}
+
+	if len(sign) != ed25519.SignatureSize || !ed25519.Verify(pub, data, sign) {
+		return ErrSignVerify
+	}
+
+	return nil
+}
+
+// bigIntBytes 获取大整数的大端字节,不足size时左侧补0.
+func bigIntBytes(n *big.Int, size int) []byte {
+	buf := n.Bytes()
+	if len(buf) < size {
+		buf = append(make([]byte, size-len(buf)), buf...)
+	}
+
+	return buf
+}
+
+// jwkEncodeInt 将大整数编码为base64url,size>0时左侧补0到定长.
+func jwkEncodeInt(n *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(bigIntBytes(n, size))
+}
+
+// jwkDecodeInt 将base64url解码为大整数.
+func jwkDecodeInt(str, name string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil || len(buf) == 0 {
+		return nil, fmt.Errorf("[JwkToPem] invalid member %q", name)
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// PemToJwk 将pem格式的密钥转换为JWK(RFC 7517)的json,支持RSA/ECDSA/Ed25519的公钥和私钥.
+func (ke *LkkEncrypt) PemToJwk(key []byte) ([]byte, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("[PemToJwk] pem key error")
+	}
+
+	var k interface{}
+	var err error
+	if strings.Contains(block.Type, "PRIVATE") {
+		k, err = parsePemPrivateKey(key)
+	} else {
+		k, err = parsePemPublicKey(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var jwk jsonWebKey
+	switch v := k.(type) {
+	case *rsa.PrivateKey:
+		v.Precompute()
+		jwk = jsonWebKey{
+			Kty: "RSA",
+			N:   jwkEncodeInt(v.N, 0),
+			E:   jwkEncodeInt(big.NewInt(int64(v.E)), 0),
+			D:   jwkEncodeInt(v.D, 0),
+		}
+		if len(v.Primes) == 2 {
+			jwk.P = jwkEncodeInt(v.Primes[0], 0)
+			jwk.Q = jwkEncodeInt(v.Primes[1], 0)
+			jwk.Dp = jwkEncodeInt(v.Precomputed.Dp, 0)
+			jwk.Dq = jwkEncodeInt(v.Precomputed.Dq, 0)
+			jwk.Qi = jwkEncodeInt(v.Precomputed.Qinv, 0)
+		}
+	case *rsa.PublicKey:
+		jwk = jsonWebKey{Kty: "RSA", N: jwkEncodeInt(v.N, 0), E: jwkEncodeInt(big.NewInt(int64(v.E)), 0)}
+	case *ecdsa.PrivateKey:
+		size := (v.Curve.Params().BitSize + 7) / 8
+		jwk = jsonWebKey{
+			Kty: "EC",
+			Crv: v.Curve.Params().Name,
+			X:   jwkEncodeInt(v.X, size),
+			Y:   jwkEncodeInt(v.Y, size),
+			D:   jwkEncodeInt(v.D, size),
+		}
+	case *ecdsa.PublicKey:
+		size := (v.Curve.Params().BitSize + 7) / 8
+		jwk = jsonWebKey{Kty: "EC", Crv: v.Curve.Params().Name, X: jwkEncodeInt(v.X, size), Y: jwkEncodeInt(v.Y, size)}
+	case ed25519.PrivateKey:
+		jwk = jsonWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(v.Public().(ed25519.PublicKey)),
+			D:   base64.RawURLEncoding.EncodeToString(v.Seed()),
+		}
+	case ed25519.PublicKey:
+		jwk = jsonWebKey{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(v)}
+	default:
+		return nil, fmt.Errorf("[PemToJwk] unsupported key type %T", k)
+	}
+
+	return json.Marshal(jwk)
+}
+
+// JwkToPem 将JWK(RFC 7517)的json转换为pem格式的密钥,私钥为PKCS8格式,公钥为PKIX格式.
+// 支持RSA/EC(P-256,P-384,P-521)/OKP(Ed25519);含有d成员时为私钥.
+func (ke *LkkEncrypt) JwkToPem(jwkJson []byte) ([]byte, error) {
+	var jwk jsonWebKey
+	if err := json.Unmarshal(jwkJson, &jwk); err != nil {
+		return nil, err
+	}
+
+	var priv, pub interface{}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := jwkDecodeInt(jwk.N, "n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkDecodeInt(jwk.E, "e")
+		if err != nil {
+			return nil, err
+		} else if !e.IsInt64() || e.Int64() > math.MaxInt32 {
+			return nil, errors.New("[JwkToPem] invalid member \"e\"")
+		}
+		pubKey := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		pub = pubKey
+
+		if jwk.D != "" {
+			d, err := jwkDecodeInt(jwk.D, "d")
+			if err != nil {
+				return nil, err
+			}
+			p, err := jwkDecodeInt(jwk.P, "p")
+			if err != nil {
+				return nil, err
+			}
+			q, err := jwkDecodeInt(jwk.Q, "q")
+			if err != nil {
+				return nil, err
+			}
+			privKey := &rsa.PrivateKey{PublicKey: *pubKey, D: d, Primes: []*big.Int{p, q}}
+			if err = privKey.Validate(); err != nil {
+				return nil, err
+			}
+			privKey.Precompute()
+			priv = privKey
+		}
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("[JwkToPem] unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := jwkDecodeInt(jwk.X, "x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkDecodeInt(jwk.Y, "y")
+		if err != nil {
+			return nil, err
+		} else if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("[JwkToPem] point is not on curve")
+		}
+		pubKey := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		pub = pubKey
+
+		if jwk.D != "" {
+			d, err := jwkDecodeInt(jwk.D, "d")
+			if err != nil {
+				return nil, err
+			}
+			privKey := &ecdsa.PrivateKey{PublicKey: *pubKey, D: d}
+			if cx, cy := curve.ScalarBaseMult(d.Bytes()); cx.Cmp(x) != 0 || cy.Cmp(y) != 0 {
+				return nil, errors.New("[JwkToPem] private key does not match public key")
+			}
+			priv = privKey
+		}
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("[JwkToPem] unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("[JwkToPem] invalid member \"x\"")
+		}
+		pub = ed25519.PublicKey(x)
+
+		if jwk.D != "" {
+			d, err := base64.RawURLEncoding.DecodeString(jwk.D)
+			if err != nil || len(d) != ed25519.SeedSize {
+				return nil, errors.New("[JwkToPem] invalid member \"d\"")
+			}
+			privKey := ed25519.NewKeyFromSeed(d)
+			if !bytes.Equal(privKey.Public().(ed25519.PublicKey), x) {
+				return nil, errors.New("[JwkToPem] private key does not match public key")
+			}
+			priv = privKey
+		}
+	default:
+		return nil, fmt.Errorf("[JwkToPem] unsupported key type %q", jwk.Kty)
+	}
+
+	if priv != nil {
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
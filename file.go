@@ -5,13 +5,17 @@ import (
 	"archive/zip"
 	"bufio"
 	"bytes"
+	"compress/bzip2"
+	"compress/flate"
 	"compress/gzip"
 	"crypto/md5"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"mime"
 	"net/http"
 	"os"
@@ -20,6 +24,7 @@ import (
 	"regexp"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // GetExt 获取文件的小写扩展名,不包括点"." .
@@ -113,6 +118,149 @@ func (kf *LkkFile) WriteFile(fpath string, data []byte, perm ...os.FileMode) err
 	return ioutil.WriteFile(fpath, data, p)
 }
 
+// NewAtomicWriter 创建原子写文件的io.WriteCloser,写入同目录的临时文件,Close时fsync后重命名覆盖fpath并fsync目录,
+// 断电或中途崩溃时fpath要么是旧内容,要么是完整的新内容.
+// perm为文件权限,为0时沿用已有文件的权限,否则为0644;backup为true时将旧文件保留为fpath.bak.
+// 出错时应调用Abort放弃写入.
+func (kf *LkkFile) NewAtomicWriter(fpath string, perm os.FileMode, backup bool) (*AtomicWriter, error) {
+	dir := filepath.Dir(fpath)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if perm == 0 {
+		perm = 0644
+		if stat, err := os.Stat(fpath); err == nil {
+			perm = stat.Mode().Perm()
+		}
+	}
+
+	file, err := ioutil.TempFile(dir, "."+filepath.Base(fpath)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AtomicWriter{path: fpath, perm: perm, backup: backup, file: file}, nil
+}
+
+// Write 写入临时文件.
+func (aw *AtomicWriter) Write(p []byte) (int, error) {
+	if aw.closed {
+		return 0, os.ErrClosed
+	} else if aw.err != nil {
+		return 0, aw.err
+	}
+
+	n, err := aw.file.Write(p)
+	if err != nil {
+		aw.err = err
+	}
+
+	return n, err
+}
+
+// Abort 放弃写入并删除临时文件,目标文件保持不变.
+func (aw *AtomicWriter) Abort() error {
+	if aw.closed {
+		return os.ErrClosed
+	}
+	aw.closed = true
+	_ = aw.file.Close()
+
+	return os.Remove(aw.file.Name())
+}
+
+// Close 将临时文件同步到磁盘并重命名为目标文件;写入出错时放弃写入并返回该错误.
+func (aw *AtomicWriter) Close() (err error) {
+	if aw.closed {
+		return os.ErrClosed
+	} else if aw.err != nil {
+		_ = aw.Abort()
+		return aw.err
+	}
+
+	aw.closed = true
+	tmpName := aw.file.Name()
+	defer func() {
+		if err != nil {
+			_ = aw.file.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	if err = aw.file.Chmod(aw.perm); err != nil {
+		return
+	} else if err = aw.file.Sync(); err != nil {
+		return
+	} else if err = aw.file.Close(); err != nil {
+		return
+	}
+
+	if aw.backup {
+		if err = backupFile(aw.path); err != nil {
+			return
+		}
+	}
+	if err = os.Rename(tmpName, aw.path); err != nil {
+		return
+	}
+
+	return syncDir(filepath.Dir(aw.path))
+}
+
+// backupFile 将文件fpath保留为fpath.bak,fpath不存在时忽略.
+func backupFile(fpath string) error {
+	stat, err := os.Lstat(fpath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if !stat.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", fpath)
+	}
+
+	//使用硬链接,目标文件始终存在;不支持时复制
+	bak := fpath + ".bak"
+	if err = os.Remove(bak); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.Link(fpath, bak) == nil {
+		return nil
+	}
+
+	_, err = KFile.CopyFile(fpath, bak, FILE_COVER_ALLOW)
+	return err
+}
+
+// syncDir 将目录项同步到磁盘,使重命名持久化.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = d.Close()
+	}()
+
+	return d.Sync()
+}
+
+// WriteFileAtomic 原子地将内容写入文件,断电或中途崩溃时不会留下不完整的文件.
+// fpath为文件路径,data为内容,perm为权限(为0时沿用已有文件的权限,否则为0644),backup为是否将旧文件保留为fpath.bak.
+func (kf *LkkFile) WriteFileAtomic(fpath string, data []byte, perm os.FileMode, backup ...bool) error {
+	aw, err := kf.NewAtomicWriter(fpath, perm, len(backup) > 0 && backup[0])
+	if err != nil {
+		return err
+	}
+
+	if _, err = aw.Write(data); err != nil {
+		_ = aw.Abort()
+		return err
+	}
+
+	return aw.Close()
+}
+
 // AppendFile 插入文件内容.
 func (kf *LkkFile) AppendFile(fpath string, data []byte) error {
 	if fpath == "" {
@@ -151,28 +299,333 @@ func (kf *LkkFile) GetFileMode(fpath string) (os.FileMode, error) {
 	return finfo.Mode(), nil
 }
 
-// GetMime 获取文件mime类型;fast为true时根据后缀快速获取;为false时读取文件头获取.
+// GetFileOwner 获取文件的所有者和所属组(含名称)及文件模式.
+func (kf *LkkFile) GetFileOwner(fpath string) (*FileOwnerInfo, error) {
+	finfo, err := os.Lstat(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, ok := finfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, fmt.Errorf("[GetFileOwner] %s: unsupported file info", fpath)
+	}
+
+	res := &FileOwnerInfo{
+		Uid:  int(stat.Uid),
+		Gid:  int(stat.Gid),
+		Mode: finfo.Mode(),
+	}
+	res.User, _ = KOS.GetUserNameByUid(res.Uid)
+	res.Group, _ = KOS.GetGroupNameByGid(res.Gid)
+
+	return res, nil
+}
+
+// GetMime 获取文件mime类型;fast为true时根据后缀快速获取;为false时根据文件内容识别,见DetectFileType.
 func (kf *LkkFile) GetMime(fpath string, fast bool) string {
 	var res string
 	if fast {
 		suffix := filepath.Ext(fpath)
 		res = mime.TypeByExtension(suffix)
-	} else {
-		srcFile, err := os.Open(fpath)
+	} else if ft, err := kf.DetectFileType(fpath); err == nil {
+		res = ft.Mime
+	}
+
+	return res
+}
+
+// fileSignatures 文件头签名库,按顺序匹配,较长或较特殊的签名在前.
+var fileSignatures = []fileSignature{
+	//图片
+	{magic: "\xff\xd8\xff", ext: "jpg", mime: "image/jpeg", category: FILE_CATEGORY_IMAGE},
+	{magic: "\x89PNG\r\n\x1a\n", ext: "png", mime: "image/png", category: FILE_CATEGORY_IMAGE},
+	{magic: "GIF87a", ext: "gif", mime: "image/gif", category: FILE_CATEGORY_IMAGE},
+	{magic: "GIF89a", ext: "gif", mime: "image/gif", category: FILE_CATEGORY_IMAGE},
+	{offset: 8, magic: "WEBP", match: isRiffFile, ext: "webp", mime: "image/webp", category: FILE_CATEGORY_IMAGE},
+	{magic: "BM", match: isBmpFile, ext: "bmp", mime: "image/bmp", category: FILE_CATEGORY_IMAGE},
+	{magic: "II*\x00", ext: "tif", mime: "image/tiff", category: FILE_CATEGORY_IMAGE},
+	{magic: "MM\x00*", ext: "tif", mime: "image/tiff", category: FILE_CATEGORY_IMAGE},
+	{magic: "\x00\x00\x01\x00", ext: "ico", mime: "image/x-icon", category: FILE_CATEGORY_IMAGE},
+	{magic: "8BPS", ext: "psd", mime: "image/vnd.adobe.photoshop", category: FILE_CATEGORY_IMAGE},
+	{magic: "\xff\x0a", ext: "jxl", mime: "image/jxl", category: FILE_CATEGORY_IMAGE},
+	{magic: "\x00\x00\x00\x0cJXL \r\n\x87\n", ext: "jxl", mime: "image/jxl", category: FILE_CATEGORY_IMAGE},
+
+	//视频
+	{magic: "\x1a\x45\xdf\xa3", match: isWebmFile, ext: "webm", mime: "video/webm", category: FILE_CATEGORY_VIDEO},
+	{magic: "\x1a\x45\xdf\xa3", ext: "mkv", mime: "video/x-matroska", category: FILE_CATEGORY_VIDEO},
+	{offset: 8, magic: "AVI ", match: isRiffFile, ext: "avi", mime: "video/x-msvideo", category: FILE_CATEGORY_VIDEO},
+	{magic: "FLV\x01", ext: "flv", mime: "video/x-flv", category: FILE_CATEGORY_VIDEO},
+	{magic: "\x00\x00\x01\xba", ext: "mpg", mime: "video/mpeg", category: FILE_CATEGORY_VIDEO},
+	{magic: "\x00\x00\x01\xb3", ext: "mpg", mime: "video/mpeg", category: FILE_CATEGORY_VIDEO},
+	{magic: "\x30\x26\xb2\x75\x8e\x66\xcf\x11", ext: "wmv", mime: "video/x-ms-wmv", category: FILE_CATEGORY_VIDEO},
+
+	//音频
+	{magic: "fLaC", ext: "flac", mime: "audio/flac", category: FILE_CATEGORY_AUDIO},
+	{offset: 8, magic: "WAVE", match: isRiffFile, ext: "wav", mime: "audio/wav", category: FILE_CATEGORY_AUDIO},
+	{magic: "OggS", ext: "ogg", mime: "audio/ogg", category: FILE_CATEGORY_AUDIO},
+	{magic: "ID3", ext: "mp3", mime: "audio/mpeg", category: FILE_CATEGORY_AUDIO},
+	{magic: "\xff\xfb", ext: "mp3", mime: "audio/mpeg", category: FILE_CATEGORY_AUDIO},
+	{magic: "\xff\xf3", ext: "mp3", mime: "audio/mpeg", category: FILE_CATEGORY_AUDIO},
+	{magic: "\xff\xf2", ext: "mp3", mime: "audio/mpeg", category: FILE_CATEGORY_AUDIO},
+	{magic: "\xff\xf1", ext: "aac", mime: "audio/aac", category: FILE_CATEGORY_AUDIO},
+	{magic: "\xff\xf9", ext: "aac", mime: "audio/aac", category: FILE_CATEGORY_AUDIO},
+	{magic: "#!AMR", ext: "amr", mime: "audio/amr", category: FILE_CATEGORY_AUDIO},
+	{magic: "MThd", ext: "mid", mime: "audio/midi", category: FILE_CATEGORY_AUDIO},
+
+	//压缩包
+	{magic: "PK\x03\x04", ext: "zip", mime: "application/zip", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "PK\x05\x06", ext: "zip", mime: "application/zip", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "PK\x07\x08", ext: "zip", mime: "application/zip", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "7z\xbc\xaf\x27\x1c", ext: "7z", mime: "application/x-7z-compressed", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "Rar!\x1a\x07\x00", ext: "rar", mime: "application/vnd.rar", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "Rar!\x1a\x07\x01\x00", ext: "rar", mime: "application/vnd.rar", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "\xfd7zXZ\x00", ext: "xz", mime: "application/x-xz", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "\x28\xb5\x2f\xfd", ext: "zst", mime: "application/zstd", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "\x1f\x8b", ext: "gz", mime: "application/gzip", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "BZh", ext: "bz2", mime: "application/x-bzip2", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "\x04\x22\x4d\x18", ext: "lz4", mime: "application/x-lz4", category: FILE_CATEGORY_ARCHIVE},
+	{offset: 257, magic: "ustar", ext: "tar", mime: "application/x-tar", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "MSCF\x00\x00\x00\x00", ext: "cab", mime: "application/vnd.ms-cab-compressed", category: FILE_CATEGORY_ARCHIVE},
+	{magic: "\xed\xab\xee\xdb", ext: "rpm", mime: "application/x-rpm", category: FILE_CATEGORY_ARCHIVE},
+
+	//文档
+	{magic: "%PDF-", ext: "pdf", mime: "application/pdf", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "{\\rtf", ext: "rtf", mime: "application/rtf", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "%!PS", ext: "ps", mime: "application/postscript", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", ext: "ole", mime: "application/x-ole-storage", category: FILE_CATEGORY_DOCUMENT},
+
+	//可执行文件
+	{magic: "\x7fELF", ext: "elf", mime: "application/x-elf", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "MZ", match: isPeFile, ext: "exe", mime: "application/vnd.microsoft.portable-executable", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xfe\xed\xfa\xce", ext: "macho", mime: "application/x-mach-binary", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xfe\xed\xfa\xcf", ext: "macho", mime: "application/x-mach-binary", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xce\xfa\xed\xfe", ext: "macho", mime: "application/x-mach-binary", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xcf\xfa\xed\xfe", ext: "macho", mime: "application/x-mach-binary", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xca\xfe\xba\xbe", match: isJavaClass, ext: "class", mime: "application/java-vm", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\xca\xfe\xba\xbe", ext: "macho", mime: "application/x-mach-binary", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "\x00asm", ext: "wasm", mime: "application/wasm", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "dex\n", ext: "dex", mime: "application/vnd.android.dex", category: FILE_CATEGORY_EXECUTABLE},
+
+	//字体
+	{magic: "wOF2", ext: "woff2", mime: "font/woff2", category: FILE_CATEGORY_FONT},
+	{magic: "wOFF", ext: "woff", mime: "font/woff", category: FILE_CATEGORY_FONT},
+	{magic: "\x00\x01\x00\x00\x00", ext: "ttf", mime: "font/ttf", category: FILE_CATEGORY_FONT},
+	{magic: "OTTO", ext: "otf", mime: "font/otf", category: FILE_CATEGORY_FONT},
+	{magic: "ttcf", ext: "ttc", mime: "font/collection", category: FILE_CATEGORY_FONT},
+
+	//文本格式的图片
+	{match: isSvgFile, ext: "svg", mime: "image/svg+xml", category: FILE_CATEGORY_IMAGE},
+}
+
+// zipSubTypes zip格式的文档等,按条目名称前缀识别.
+var zipSubTypes = []fileSignature{
+	{magic: "word/", ext: "docx", mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "xl/", ext: "xlsx", mime: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "ppt/", ext: "pptx", mime: "application/vnd.openxmlformats-officedocument.presentationml.presentation", category: FILE_CATEGORY_DOCUMENT},
+	{magic: "AndroidManifest.xml", ext: "apk", mime: "application/vnd.android.package-archive", category: FILE_CATEGORY_EXECUTABLE},
+	{magic: "META-INF/MANIFEST.MF", ext: "jar", mime: "application/java-archive", category: FILE_CATEGORY_ARCHIVE},
+}
+
+// zipMimeTypes zip格式的文档,按mimetype条目的内容识别.
+var zipMimeTypes = map[string]string{
+	"application/vnd.oasis.opendocument.text":         "odt",
+	"application/vnd.oasis.opendocument.spreadsheet":  "ods",
+	"application/vnd.oasis.opendocument.presentation": "odp",
+	"application/epub+zip":                            "epub",
+}
+
+// isRiffFile 是否RIFF容器.
+func isRiffFile(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte("RIFF"))
+}
+
+// isBmpFile 是否bmp图片,其文件头的保留字段须为0.
+func isBmpFile(buf []byte) bool {
+	return len(buf) >= 14 && binary.LittleEndian.Uint32(buf[6:10]) == 0
+}
+
+// isWebmFile Matroska容器的文档类型是否webm.
+func isWebmFile(buf []byte) bool {
+	if len(buf) > 64 {
+		buf = buf[:64]
+	}
+	return bytes.Contains(buf, []byte("webm"))
+}
+
+// isPeFile 是否PE格式的可执行文件.
+func isPeFile(buf []byte) bool {
+	if len(buf) < 64 {
+		return false
+	}
+	pos := int(binary.LittleEndian.Uint32(buf[60:64]))
+	return pos > 0 && pos+4 <= len(buf) && string(buf[pos:pos+4]) == "PE\x00\x00"
+}
+
+// isJavaClass 是否java类文件;与Mach-O通用二进制的魔数相同,后者随后为架构数量.
+func isJavaClass(buf []byte) bool {
+	return len(buf) >= 8 && binary.BigEndian.Uint32(buf[4:8]) >= 45
+}
+
+// isSvgFile 是否svg图片,跳过开头的xml声明、注释和doctype.
+func isSvgFile(buf []byte) bool {
+	buf = bytes.TrimPrefix(buf, []byte("\xef\xbb\xbf"))
+	for {
+		buf = bytes.TrimLeft(buf, " \t\r\n")
+		if bytes.HasPrefix(buf, []byte("<svg")) {
+			return true
+		}
+
+		var end string
+		switch {
+		case bytes.HasPrefix(buf, []byte("<?")):
+			end = "?>"
+		case bytes.HasPrefix(buf, []byte("<!--")):
+			end = "-->"
+		case bytes.HasPrefix(buf, []byte("<!")):
+			end = ">"
+		default:
+			return false
+		}
+
+		pos := bytes.Index(buf, []byte(end))
+		if pos < 0 {
+			return false
+		}
+		buf = buf[pos+len(end):]
+	}
+}
+
+// detectFtyp 识别ISO媒体格式(mp4、mov、heic、avif等),根据ftyp中的主品牌和兼容品牌判断;非ftyp或未知品牌时返回nil.
+func detectFtyp(buf []byte) *FileTypeInfo {
+	if len(buf) < 12 || string(buf[4:8]) != "ftyp" {
+		return nil
+	}
+
+	size := int(binary.BigEndian.Uint32(buf[0:4]))
+	if size > len(buf) {
+		size = len(buf)
+	}
+	major := string(buf[8:12])
+	brands := map[string]bool{major: true}
+	for i := 16; i+4 <= size; i += 4 {
+		brands[string(buf[i:i+4])] = true
+	}
+
+	switch {
+	case major == "avif" || major == "avis" || (major == "mif1" || major == "msf1") && brands["avif"]:
+		return &FileTypeInfo{Ext: "avif", Mime: "image/avif", Category: FILE_CATEGORY_IMAGE}
+	case major == "heic" || major == "heix" || major == "hevc" || major == "hevx" || major == "heim" || major == "heis" || major == "mif1" || major == "msf1":
+		return &FileTypeInfo{Ext: "heic", Mime: "image/heic", Category: FILE_CATEGORY_IMAGE}
+	case major == "M4A " || major == "M4B ":
+		return &FileTypeInfo{Ext: "m4a", Mime: "audio/mp4", Category: FILE_CATEGORY_AUDIO}
+	case major == "M4V ":
+		return &FileTypeInfo{Ext: "m4v", Mime: "video/x-m4v", Category: FILE_CATEGORY_VIDEO}
+	case major == "qt  ":
+		return &FileTypeInfo{Ext: "mov", Mime: "video/quicktime", Category: FILE_CATEGORY_VIDEO}
+	case strings.HasPrefix(major, "3g2"):
+		return &FileTypeInfo{Ext: "3g2", Mime: "video/3gpp2", Category: FILE_CATEGORY_VIDEO}
+	case strings.HasPrefix(major, "3gp"):
+		return &FileTypeInfo{Ext: "3gp", Mime: "video/3gpp", Category: FILE_CATEGORY_VIDEO}
+	case major == "isom" || major == "iso2" || major == "iso4" || major == "iso5" || major == "iso6" || major == "mp41" || major == "mp42" || major == "avc1" || major == "dash" || major == "f4v ":
+		return &FileTypeInfo{Ext: "mp4", Mime: "video/mp4", Category: FILE_CATEGORY_VIDEO}
+	}
+
+	//未知品牌,如CR3等相机原始格式
+	return nil
+}
+
+// detectZipType 根据zip的中央目录识别docx、xlsx、odt、jar等基于zip的格式,无法识别时返回nil.
+func detectZipType(r io.ReaderAt, size int64) *FileTypeInfo {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil
+	}
+
+	for _, f := range zr.File {
+		if f.Name == "mimetype" && f.UncompressedSize64 < 128 {
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			cont, _ := ioutil.ReadAll(rc)
+			_ = rc.Close()
+			mimeType := strings.TrimSpace(string(cont))
+			if ext, ok := zipMimeTypes[mimeType]; ok {
+				return &FileTypeInfo{Ext: ext, Mime: mimeType, Category: FILE_CATEGORY_DOCUMENT}
+			}
+		}
+
+		for _, sig := range zipSubTypes {
+			if strings.HasPrefix(f.Name, sig.magic) {
+				return &FileTypeInfo{Ext: sig.ext, Mime: sig.mime, Category: sig.category}
+			}
+		}
+	}
+
+	return nil
+}
+
+// detectFileType 根据文件头head识别文件类型;r和size为完整内容,用于zip格式的进一步识别,可为nil.
+func detectFileType(head []byte, r io.ReaderAt, size int64) *FileTypeInfo {
+	if res := detectFtyp(head); res != nil {
+		return res
+	}
+
+	for _, sig := range fileSignatures {
+		end := sig.offset + len(sig.magic)
+		if end > len(head) || string(head[sig.offset:end]) != sig.magic || (sig.match != nil && !sig.match(head)) {
+			continue
+		}
+
+		if sig.ext == "zip" && r != nil {
+			if res := detectZipType(r, size); res != nil {
+				return res
+			}
+		}
+		return &FileTypeInfo{Ext: sig.ext, Mime: sig.mime, Category: sig.category}
+	}
+
+	//未知类型
+	return &FileTypeInfo{Mime: http.DetectContentType(head), Category: FILE_CATEGORY_UNKNOWN}
+}
+
+// DetectFileType 根据文件内容(魔数)识别文件类型,而非扩展名;src为文件路径string或文件内容[]byte.
+// 无法识别时Ext为空,Category为FILE_CATEGORY_UNKNOWN,Mime由http.DetectContentType给出.
+// 可识别常见的图片、音视频、压缩包、文档、可执行文件和字体,包括webp、heic、avif、docx、xlsx、7z、zst、wasm、mkv、flac、woff2等.
+func (kf *LkkFile) DetectFileType(src interface{}) (*FileTypeInfo, error) {
+	switch v := src.(type) {
+	case []byte:
+		head := v
+		if len(head) > 8192 {
+			head = head[:8192]
+		}
+		return detectFileType(head, bytes.NewReader(v), int64(len(v))), nil
+	case string:
+		f, err := os.Open(v)
 		if err != nil {
-			return res
+			return nil, err
 		}
+		defer func() {
+			_ = f.Close()
+		}()
 
-		buffer := make([]byte, 512)
-		_, err = srcFile.Read(buffer)
+		stat, err := f.Stat()
 		if err != nil {
-			return res
+			return nil, err
+		} else if !stat.Mode().IsRegular() {
+			return nil, fmt.Errorf("[DetectFileType] %s is not a regular file", v)
 		}
 
-		res = http.DetectContentType(buffer)
+		head := make([]byte, 8192)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, err
+		}
+		return detectFileType(head[:n], f, stat.Size()), nil
+	default:
+		return nil, fmt.Errorf("[DetectFileType] unsupported type %T", src)
 	}
-
-	return res
 }
 
 // FileSize 获取文件大小(bytes字节),注意:文件不存在或无法访问返回-1 .
@@ -224,6 +677,121 @@ func (kf *LkkFile) IsReadable(fpath string) bool {
 	return true
 }
 
+// Error 实现error接口.
+func (e *AccessError) Error() string {
+	return fmt.Sprintf("[CheckAccess] %s: %s denied: %s", e.Path, e.Perm, e.Err.Error())
+}
+
+// Unwrap 返回原始错误.
+func (e *AccessError) Unwrap() error {
+	return e.Err
+}
+
+// accessBits 将r/w/x转换为权限位(以other位表示).
+func accessBits(perm string) (bits uint32) {
+	if strings.Contains(perm, "r") {
+		bits |= 4
+	}
+	if strings.Contains(perm, "w") {
+		bits |= 2
+	}
+	if strings.Contains(perm, "x") {
+		bits |= 1
+	}
+	return
+}
+
+// inGroups 当前进程是否属于该组.
+func inGroups(gid uint32) bool {
+	if uint32(os.Getegid()) == gid {
+		return true
+	}
+	groups, _ := os.Getgroups()
+	for _, g := range groups {
+		if uint32(g) == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnoseAccess 根据所有者和权限位分析被拒绝的原因,允许访问时返回nil.
+func diagnoseAccess(stat *syscall.Stat_t, bits uint32) error {
+	// Stat_t.Mode在darwin上为uint16
+	mode := uint32(stat.Mode)
+	owner, group, other := (mode>>6)&7, (mode>>3)&7, mode&7
+	euid := uint32(os.Geteuid())
+	if euid == 0 {
+		// root仅在需要执行权限且无任何x位时被拒绝
+		if bits&1 == 0 || (owner|group|other)&1 != 0 || mode&syscall.S_IFMT == syscall.S_IFDIR {
+			return nil
+		}
+		return ErrAccessMode
+	}
+
+	var granted uint32
+	if euid == stat.Uid {
+		granted = owner
+	} else if inGroups(stat.Gid) {
+		granted = group
+	} else {
+		granted = other
+	}
+	if granted&bits == bits {
+		return nil
+	} else if euid != stat.Uid && ((owner&bits == bits) || (group&bits == bits)) {
+		return ErrAccessOwner
+	}
+	return ErrAccessMode
+}
+
+// CheckAccess 检查当前用户对路径是否有perm(r/w/x的组合,如"rw")权限;
+// 无权限时返回*AccessError,其Err为ErrAccessOwner、ErrAccessMode或ErrAccessReadOnly等原因.
+func (kf *LkkFile) CheckAccess(fpath string, perm string) error {
+	absPath, err := filepath.Abs(fpath)
+	if err != nil {
+		return err
+	}
+
+	// 逐级检查上级目录的搜索权限
+	dirs := []string{}
+	for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if dir == "/" || dir == "." {
+			break
+		}
+	}
+	for _, dir := range dirs {
+		var st syscall.Stat_t
+		if err = syscall.Stat(dir, &st); err != nil {
+			return &os.PathError{Op: "stat", Path: dir, Err: err}
+		}
+		if e := diagnoseAccess(&st, 1); e != nil {
+			return &AccessError{Path: dir, Perm: "x", Err: e}
+		}
+	}
+
+	var st syscall.Stat_t
+	if err = syscall.Stat(absPath, &st); err != nil {
+		return &os.PathError{Op: "stat", Path: absPath, Err: err}
+	}
+
+	bits := accessBits(perm)
+	if e := diagnoseAccess(&st, bits); e != nil {
+		return &AccessError{Path: absPath, Perm: perm, Err: e}
+	}
+
+	// 权限位允许,再由内核确认(只读挂载、ACL等)
+	if err = syscall.Access(absPath, bits); err != nil {
+		if err == syscall.EROFS {
+			return &AccessError{Path: absPath, Perm: perm, Err: ErrAccessReadOnly}
+		}
+		return &AccessError{Path: absPath, Perm: perm, Err: err}
+	}
+
+	return nil
+}
+
 // IsExecutable 是否可执行文件.
 func (kf *LkkFile) IsExecutable(fpath string) bool {
 	info, err := os.Stat(fpath)
@@ -297,8 +865,14 @@ func (kf *LkkFile) IsBinary(fpath string) bool {
 	return KConv.IsBinary(string(cont))
 }
 
-// IsImg 是否图片文件(仅检查后缀).
-func (kf *LkkFile) IsImg(fpath string) bool {
+// IsImg 是否图片文件;默认仅检查后缀,strict为true时根据文件内容判断,见DetectFileType.
+// 校验上传文件等不可信的来源时应使用strict;svg可包含脚本,strict模式下不视为图片,需要时可用IsSvg单独检查.
+func (kf *LkkFile) IsImg(fpath string, strict ...bool) bool {
+	if len(strict) > 0 && strict[0] {
+		ft, err := kf.DetectFileType(fpath)
+		return err == nil && ft.Category == FILE_CATEGORY_IMAGE && ft.Ext != "svg"
+	}
+
 	ext := kf.GetExt(fpath)
 	switch ext {
 	case "jpg", "jpeg", "bmp", "gif", "png", "svg", "ico", "webp":
@@ -308,6 +882,13 @@ func (kf *LkkFile) IsImg(fpath string) bool {
 	}
 }
 
+// IsSvg 根据文件内容判断是否svg图片.
+// 注意svg为可包含脚本的xml,作为不可信的上传文件时须另行过滤或以附件形式提供下载,否则可导致存储型XSS.
+func (kf *LkkFile) IsSvg(fpath string) bool {
+	ft, err := kf.DetectFileType(fpath)
+	return err == nil && ft.Ext == "svg"
+}
+
 // Mkdir 新建目录,允许多级目录.
 func (kf *LkkFile) Mkdir(fpath string, mode os.FileMode) error {
 	return os.MkdirAll(fpath, mode)
@@ -799,6 +1380,26 @@ func (kf *LkkFile) Glob(pattern string) ([]string, error) {
 
 // TarGz 打包压缩tar.gz;src为源文件或目录,dstTar为打包的路径名,ignorePatterns为要忽略的文件正则.
 func (kf *LkkFile) TarGz(src string, dstTar string, ignorePatterns ...string) (bool, error) {
+	return kf.TarGzLevel(src, dstTar, gzip.DefaultCompression, ignorePatterns...)
+}
+
+// TarGzLevel 以指定的压缩级别打包压缩tar.gz;level取值为gzip.HuffmanOnly到gzip.BestCompression,其余参数同TarGz.
+func (kf *LkkFile) TarGzLevel(src string, dstTar string, level int, ignorePatterns ...string) (bool, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return false, fmt.Errorf("gzip: invalid compression level: %d", level)
+	}
+
+	return kf.tarPack(src, dstTar, true, level, ignorePatterns)
+}
+
+// Tar 打包为不压缩的tar文件;参数同TarGz.
+func (kf *LkkFile) Tar(src string, dstTar string, ignorePatterns ...string) (bool, error) {
+	return kf.tarPack(src, dstTar, false, 0, ignorePatterns)
+}
+
+// tarPack 打包tar,compress为true时以level级别进行gzip压缩.
+// 保留文件的权限和修改时间,符号链接按链接本身打包,不打包链接目录下的文件.
+func (kf *LkkFile) tarPack(src string, dstTar string, compress bool, level int, ignorePatterns []string) (bool, error) {
 	//过滤器,检查要忽略的文件
 	var filter = func(file string) bool {
 		res := true
@@ -826,7 +1427,7 @@ func (kf *LkkFile) TarGz(src string, dstTar string, ignorePatterns ...string) (b
 
 	files := kf.FileTree(src, FILE_TREE_ALL, true, filter)
 	if len(files) == 0 {
-		return false, fmt.Errorf("src no files to tar.gz")
+		return false, fmt.Errorf("src no files to tar")
 	}
 
 	// dest file write
@@ -837,139 +1438,493 @@ func (kf *LkkFile) TarGz(src string, dstTar string, ignorePatterns ...string) (b
 	defer func() {
 		_ = fw.Close()
 	}()
-	// gzip write
-	gw := gzip.NewWriter(fw)
-	defer func() {
-		_ = gw.Close()
-	}()
+
+	var w io.Writer = fw
+	if compress {
+		// gzip write
+		gw, err := gzip.NewWriterLevel(fw, level)
+		if err != nil {
+			return false, err
+		}
+		defer func() {
+			_ = gw.Close()
+		}()
+		w = gw
+	}
 
 	// tar write
-	tw := tar.NewWriter(gw)
+	tw := tar.NewWriter(w)
 	defer func() {
 		_ = tw.Close()
 	}()
 
 	parentDir := filepath.Dir(src)
+	var links []string
 	for _, file := range files {
-		if file == dstTar {
+		if file == dstTar || hasPathPrefix(file, links) {
 			continue
 		}
-		fi, err := os.Stat(file)
+		fi, err := os.Lstat(file)
 		if err != nil {
 			continue
 		}
 		newName := strings.Replace(file, parentDir, "", -1)
 
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return false, fmt.Errorf("LinkErr: %s file:%s\n", err.Error(), file)
+			}
+			links = append(links, file)
+		}
+
 		// Create tar header
-		hdr := new(tar.Header)
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			continue
+		}
 		hdr.Format = tar.FormatGNU
+		hdr.Name = newName
 
 		if fi.IsDir() {
 			// if last character of header name is '/' it also can be directory
 			// but if you don't set Typeflag, error will occur when you untargz
 			hdr.Name = newName + "/"
-			hdr.Typeflag = tar.TypeDir
-			hdr.Size = 0
-			//hdr.Mode = 0755 | c_ISDIR
-			hdr.Mode = int64(fi.Mode())
-			hdr.ModTime = fi.ModTime()
 
 			// Write hander
 			err := tw.WriteHeader(hdr)
 			if err != nil {
 				return false, fmt.Errorf("DirErr: %s file:%s\n", err.Error(), file)
 			}
+		} else if hdr.Typeflag != tar.TypeReg {
+			// Write hander
+			err := tw.WriteHeader(hdr)
+			if err != nil {
+				return false, fmt.Errorf("FileErr: %s file:%s\n", err.Error(), file)
+			}
 		} else {
 			// File reader
 			fr, err := os.Open(file)
 			if err != nil {
 				return false, fmt.Errorf("OpenErr: %s file:%s\n", err.Error(), file)
 			}
-			defer func() {
-				_ = fr.Close()
-			}()
-
-			hdr.Name = newName
-			hdr.Size = fi.Size()
-			hdr.Mode = int64(fi.Mode())
-			hdr.ModTime = fi.ModTime()
 
 			// Write hander
 			err = tw.WriteHeader(hdr)
 			if err != nil {
+				_ = fr.Close()
 				return false, fmt.Errorf("FileErr: %s file:%s\n", err.Error(), file)
 			}
 
 			// Write file data
 			_, err = io.Copy(tw, fr)
+			_ = fr.Close()
 			if err != nil {
 				return false, fmt.Errorf("CopyErr: %s file:%s\n", err.Error(), file)
 			}
-			_ = fr.Close()
 		}
 	}
 
 	return true, nil
 }
 
-// UnTarGz 将tar.gz文件解压缩;srcTar为压缩包,dstDir为解压目录.
-func (kf *LkkFile) UnTarGz(srcTar, dstDir string) (bool, error) {
-	fr, err := os.Open(srcTar)
-	if err != nil {
-		return false, err
-	}
-	defer func() {
-		_ = fr.Close()
-	}()
-
-	dstDir = strings.TrimRight(kf.AbsPath(dstDir), "/\\")
-	if !kf.IsExist(dstDir) {
-		err := kf.Mkdir(dstDir, os.ModePerm)
-		if err != nil {
-			return false, err
+// hasPathPrefix 检查fpath是否位于dirs中某个目录之下.
+func hasPathPrefix(fpath string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(fpath, dir+"/") {
+			return true
 		}
 	}
 
-	// Gzip reader
-	gr, err := gzip.NewReader(fr)
+	return false
+}
 
-	// Tar reader
-	tr := tar.NewReader(gr)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			// End of tar archive
-			break
-		} else if err != nil {
-			return false, err
+// Error 实现error接口.
+func (e *ExtractError) Error() string {
+	return fmt.Sprintf("[Extract] %s: %s", e.Entry, e.Err.Error())
+}
+
+// Unwrap 返回原因,以便使用errors.Is判断.
+func (e *ExtractError) Unwrap() error {
+	return e.Err
+}
+
+// newArchiveExtractor 创建解压器,dstDir不存在时创建;archiveSize为压缩包大小,用于压缩比限制.
+// 未指定选项时不限制,不解压符号链接,并覆盖已存在的文件.
+func newArchiveExtractor(dstDir string, archiveSize int64, opts []ExtractOptions) (*archiveExtractor, error) {
+	ex := &archiveExtractor{maxSize: -1}
+	if len(opts) > 0 {
+		ex.opts = opts[0]
+	}
+
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	realDir, err := filepath.EvalSymlinks(dstDir)
+	if err != nil {
+		return nil, err
+	} else if ex.dstDir, err = filepath.Abs(realDir); err != nil {
+		return nil, err
+	}
+
+	if ex.opts.MaxBytes > 0 {
+		ex.maxSize = ex.opts.MaxBytes
+	}
+	if ex.opts.MaxRatio > 0 {
+		n := ex.opts.MaxRatio * float64(archiveSize)
+		if n < math.MaxInt64 && (ex.maxSize < 0 || int64(n) < ex.maxSize) {
+			ex.maxSize = int64(n)
 		}
+	}
 
-		// Create diretory before create file
-		newPath := dstDir + "/" + strings.TrimLeft(hdr.Name, "/\\")
-		parentDir := path.Dir(newPath)
-		if !kf.IsExist(parentDir) {
-			_ = os.MkdirAll(parentDir, os.ModePerm)
+	return ex, nil
+}
+
+// cleanEntryName 规范化压缩包条目名称,统一使用/分隔并去掉开头的/.
+func cleanEntryName(name string) string {
+	return path.Clean(strings.TrimLeft(strings.Replace(name, "\\", "/", -1), "/"))
+}
+
+// entryPath 将条目名称转换为相对于解压目录的路径,开头的/被忽略;返回空串表示解压目录本身.
+func entryPath(name string) (string, error) {
+	rel := cleanEntryName(name)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", ErrUnsafePath
+	} else if rel == "." {
+		return "", nil
+	}
+
+	return rel, nil
+}
+
+// contains 真实路径fpath是否在解压目录内.
+func (ex *archiveExtractor) contains(fpath string) bool {
+	rel, err := filepath.Rel(ex.dstDir, fpath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// mkdirAll 在解压目录内逐级创建目录rel并返回其真实路径;经由指向解压目录外的符号链接时返回ErrUnsafePath.
+func (ex *archiveExtractor) mkdirAll(rel string) (string, error) {
+	cur := ex.dstDir
+	for _, name := range strings.Split(rel, "/") {
+		cur = filepath.Join(cur, name)
+		stat, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			if err = os.Mkdir(cur, os.ModePerm); err != nil {
+				return "", err
+			}
+			continue
+		} else if err != nil {
+			return "", err
 		}
 
-		if hdr.Typeflag != tar.TypeDir {
-			// Write data to file
-			fw, err := os.Create(newPath)
-			if err != nil {
-				return false, fmt.Errorf("CreateErr: %s file:%s\n", err.Error(), newPath)
+		if stat.Mode()&os.ModeSymlink != 0 {
+			if cur, err = filepath.EvalSymlinks(cur); err != nil {
+				return "", err
+			} else if !ex.contains(cur) {
+				return "", ErrUnsafePath
 			}
+		} else if !stat.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", cur)
+		}
+	}
 
-			_, err = io.Copy(fw, tr)
-			if err != nil {
-				return false, fmt.Errorf("CopyErr: %s file:%s\n", err.Error(), newPath)
+	return cur, nil
+}
+
+// prepare 创建条目的上级目录,并按覆盖策略处理已存在的目标;返回目标路径,skip为true时应跳过该条目.
+func (ex *archiveExtractor) prepare(rel string) (fpath string, skip bool, err error) {
+	dir, err := ex.mkdirAll(path.Dir(rel))
+	if err != nil {
+		return
+	}
+
+	fpath = filepath.Join(dir, path.Base(rel))
+	if _, err = os.Lstat(fpath); os.IsNotExist(err) {
+		err = nil
+	} else if err == nil {
+		switch ex.opts.Existing {
+		case EXISTING_OVERWRITE:
+			err = os.Remove(fpath)
+		case EXISTING_SKIP:
+			skip = true
+		default:
+			err = os.ErrExist
+		}
+	}
+
+	return
+}
+
+// addFile 文件数加1,超出限制时返回错误.
+func (ex *archiveExtractor) addFile() error {
+	ex.files++
+	if ex.opts.MaxFiles > 0 && ex.files > ex.opts.MaxFiles {
+		return fmt.Errorf("%w: more than %d files", ErrExtractLimit, ex.opts.MaxFiles)
+	}
+
+	return nil
+}
+
+// mkdir 解压目录条目,mode非0时设置目录权限,但保留所有者的读写执行权限以便继续解压.
+func (ex *archiveExtractor) mkdir(name string, mode os.FileMode) error {
+	rel, err := entryPath(name)
+	if err != nil {
+		return err
+	}
+
+	dir, err := ex.mkdirAll(rel)
+	if err == nil && rel != "" && mode.Perm() != 0 {
+		err = os.Chmod(dir, mode.Perm()|0700)
+	}
+
+	return err
+}
+
+// writeFile 解压普通文件条目,按实际写入的字节数检查限制,超出时删除该文件.
+// mode非0时设置文件权限(不含setuid等特殊位),mtime非零时设置修改时间.
+func (ex *archiveExtractor) writeFile(name string, r io.Reader, mode os.FileMode, mtime time.Time) error {
+	rel, err := entryPath(name)
+	if err != nil {
+		return err
+	} else if rel == "" {
+		return ErrUnsafePath
+	} else if err = ex.addFile(); err != nil {
+		return err
+	}
+
+	fpath, skip, err := ex.prepare(rel)
+	if err != nil || skip {
+		return err
+	}
+
+	fw, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+
+	if ex.maxSize >= 0 {
+		r = io.LimitReader(r, ex.maxSize-ex.written+1)
+	}
+	n, err := io.Copy(fw, r)
+	ex.written += n
+	if cerr := fw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && ex.maxSize >= 0 && ex.written > ex.maxSize {
+		err = fmt.Errorf("%w: more than %d bytes", ErrExtractLimit, ex.maxSize)
+	}
+	if err == nil && mode.Perm() != 0 {
+		err = os.Chmod(fpath, mode.Perm())
+	}
+	if err == nil && !mtime.IsZero() {
+		err = os.Chtimes(fpath, mtime, mtime)
+	}
+	if err != nil {
+		_ = os.Remove(fpath)
+	}
+
+	return err
+}
+
+// checkLink 检查符号链接的目标是否位于解压目录内,dir为链接所在目录的真实路径.
+// 目标中只允许开头出现"..",否则其指向会随之后解压的链接而改变;已存在的部分按解析链接后的真实路径检查.
+func (ex *archiveExtractor) checkLink(dir, linkname string) error {
+	lead := true
+	for _, name := range strings.Split(linkname, "/") {
+		switch name {
+		case "", ".":
+		case "..":
+			if !lead {
+				return ErrUnsafeLink
 			}
+		default:
+			lead = false
+		}
+	}
+
+	target := linkname
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	target = filepath.Clean(target)
+	if !ex.contains(target) {
+		return ErrUnsafeLink
+	}
+
+	//逐级向上找到可解析的路径,检查其真实路径
+	for cur := target; ; cur = filepath.Dir(cur) {
+		if real, err := filepath.EvalSymlinks(cur); err == nil {
+			if !ex.contains(real) {
+				return ErrUnsafeLink
+			}
+			return nil
+		} else if cur == ex.dstDir {
+			return err
+		}
+	}
+}
+
+// symlink 按符号链接策略解压链接条目,linkname为链接的目标.
+func (ex *archiveExtractor) symlink(name, linkname string) error {
+	if ex.opts.Symlink == SYMLINK_SKIP {
+		return nil
+	}
+
+	rel, err := entryPath(name)
+	if err != nil {
+		return err
+	} else if rel == "" {
+		return ErrUnsafePath
+	}
+
+	if ex.opts.Symlink != SYMLINK_ALLOW {
+		//相对链接以上级目录的真实路径为准
+		dir, err := ex.mkdirAll(path.Dir(rel))
+		if err != nil {
+			return err
+		} else if err = ex.checkLink(dir, linkname); err != nil {
+			return err
+		}
+	}
+
+	if err = ex.addFile(); err != nil {
+		return err
+	}
+	fpath, skip, err := ex.prepare(rel)
+	if err != nil || skip {
+		return err
+	}
+
+	return os.Symlink(linkname, fpath)
+}
+
+// hardlink 解压硬链接条目,链接目标须为解压目录内已解压的文件.
+func (ex *archiveExtractor) hardlink(name, linkname string) error {
+	rel, err := entryPath(name)
+	if err != nil {
+		return err
+	}
+	lrel, err := entryPath(linkname)
+	if err != nil || rel == "" || lrel == "" {
+		return ErrUnsafeLink
+	}
+
+	target, err := filepath.EvalSymlinks(filepath.Join(ex.dstDir, filepath.FromSlash(lrel)))
+	if err != nil {
+		return err
+	} else if !ex.contains(target) {
+		return ErrUnsafeLink
+	}
+
+	if err = ex.addFile(); err != nil {
+		return err
+	}
+	fpath, skip, err := ex.prepare(rel)
+	if err != nil || skip {
+		return err
+	}
+
+	return os.Link(target, fpath)
+}
+
+// extractTar 解压tar中的一个条目,设备文件等其他类型被忽略.
+func (ex *archiveExtractor) extractTar(hdr *tar.Header, r io.Reader) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return ex.mkdir(hdr.Name, hdr.FileInfo().Mode())
+	case tar.TypeReg, tar.TypeRegA:
+		return ex.writeFile(hdr.Name, r, hdr.FileInfo().Mode(), hdr.ModTime)
+	case tar.TypeSymlink:
+		return ex.symlink(hdr.Name, hdr.Linkname)
+	case tar.TypeLink:
+		return ex.hardlink(hdr.Name, hdr.Linkname)
+	}
+
+	return nil
+}
 
-			_ = fw.Close()
+// extractZip 解压zip中的一个条目,设备文件等其他类型被忽略.
+func (ex *archiveExtractor) extractZip(f *zip.File) error {
+	mode := f.Mode()
+	if mode.IsDir() {
+		return ex.mkdir(f.Name, mode)
+	} else if !mode.IsRegular() && mode&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	if mode&os.ModeSymlink != 0 {
+		//zip中符号链接的内容为链接目标
+		target, err := ioutil.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+		return ex.symlink(f.Name, string(target))
+	}
+
+	return ex.writeFile(f.Name, rc, mode, f.FileInfo().ModTime())
+}
+
+// unTar 解压tar格式的压缩包,format为tar、tar.gz或tar.bz2.
+func (kf *LkkFile) unTar(srcTar, dstDir, format string, opts []ExtractOptions) (bool, error) {
+	tr, rc, err := openTar(srcTar, format)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	stat, err := os.Stat(srcTar)
+	if err != nil {
+		return false, err
+	}
+	ex, err := newArchiveExtractor(dstDir, stat.Size(), opts)
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return false, err
+		}
+
+		if err = ex.extractTar(hdr, tr); err != nil {
+			return false, &ExtractError{Entry: hdr.Name, Err: err}
 		}
 	}
 
 	return true, nil
 }
 
+// UnTarGz 将tar.gz文件解压缩;srcTar为压缩包,dstDir为解压目录.
+// 条目路径不会超出dstDir;opts为解压选项,可限制符号链接、总字节数、文件数和压缩比,未指定时不解压符号链接并覆盖已存在的文件.
+// 保留文件的权限和修改时间;条目出错时返回*ExtractError,此前已解压的文件会保留.
+func (kf *LkkFile) UnTarGz(srcTar, dstDir string, opts ...ExtractOptions) (bool, error) {
+	return kf.unTar(srcTar, dstDir, "tar.gz", opts)
+}
+
+// UnTar 将tar文件解包;参数和选项同UnTarGz.
+func (kf *LkkFile) UnTar(srcTar, dstDir string, opts ...ExtractOptions) (bool, error) {
+	return kf.unTar(srcTar, dstDir, "tar", opts)
+}
+
+// UnTarBz2 将tar.bz2文件解压缩;参数和选项同UnTarGz.
+func (kf *LkkFile) UnTarBz2(srcTar, dstDir string, opts ...ExtractOptions) (bool, error) {
+	return kf.unTar(srcTar, dstDir, "tar.bz2", opts)
+}
+
 // SafeFileName 将文件名转换为安全可用的字符串.
 func (kf *LkkFile) SafeFileName(str string) string {
 	name := strings.ToLower(str)
@@ -1046,7 +2001,17 @@ func (kf *LkkFile) CountLines(fpath string, buffLength int) (int, error) {
 }
 
 // Zip 将文件目录进行zip打包.fpaths为文件或目录的路径.
+// 保留文件的权限、修改时间和符号链接.
 func (kf *LkkFile) Zip(dst string, fpaths ...string) (bool, error) {
+	return kf.ZipLevel(dst, flate.DefaultCompression, fpaths...)
+}
+
+// ZipLevel 以指定的压缩级别进行zip打包;level取值为flate.HuffmanOnly到flate.BestCompression,其余参数同Zip.
+func (kf *LkkFile) ZipLevel(dst string, level int, fpaths ...string) (bool, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return false, fmt.Errorf("flate: invalid compression level: %d", level)
+	}
+
 	dst = kf.AbsPath(dst)
 	dstDir := kf.Dirname(dst)
 	if !kf.IsExist(dstDir) {
@@ -1065,20 +2030,7 @@ func (kf *LkkFile) Zip(dst string, fpaths ...string) (bool, error) {
 		return false, errors.New("No input files.")
 	}
 
-	var allfiles, files []string
-	var fpath string
-	for _, fpath = range fpaths {
-		fpath = KStr.Trim(fpath)
-		if kf.IsDir(fpath) {
-			files = kf.FileTree(fpath, FILE_TREE_FILE, true)
-			if len(files) != 0 {
-				allfiles = append(allfiles, files...)
-			}
-		} else if fpath != "" {
-			allfiles = append(allfiles, fpath)
-		}
-	}
-
+	allfiles := kf.zipCollect(fpaths)
 	if len(allfiles) == 0 {
 		return false, errors.New("No exist files.")
 	}
@@ -1087,33 +2039,231 @@ func (kf *LkkFile) Zip(dst string, fpaths ...string) (bool, error) {
 	defer func() {
 		_ = zipw.Close()
 	}()
+	zipw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	})
 
-	keys := make(map[string]bool)
-	for _, fpath = range allfiles {
+	if err = kf.zipWrite(zipw, dst, allfiles, make(map[string]bool)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// zipCollect 收集待打包的文件,目录展开为其下的所有文件和符号链接,不进入链接的目录.
+func (kf *LkkFile) zipCollect(fpaths []string) []string {
+	var allfiles []string
+	for _, fpath := range fpaths {
+		fpath = KStr.Trim(fpath)
+		if kf.IsDir(fpath) {
+			_ = filepath.Walk(fpath, func(file string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					allfiles = append(allfiles, file)
+				}
+				return nil
+			})
+		} else if fpath != "" {
+			allfiles = append(allfiles, fpath)
+		}
+	}
+
+	return allfiles
+}
+
+// zipWrite 将文件写入zip,跳过keys中已有的条目和zip文件dst自身.
+// 条目保留文件的权限和修改时间,符号链接的内容为其链接目标.
+func (kf *LkkFile) zipWrite(zipw *zip.Writer, dst string, files []string, keys map[string]bool) error {
+	for _, fpath := range files {
 		if _, ok := keys[fpath]; ok || kf.AbsPath(fpath) == dst {
 			continue
 		}
 
-		fileToZip, err := os.Open(fpath)
+		fi, err := os.Lstat(fpath)
 		if err != nil {
-			return false, fmt.Errorf("Failed to open %s: %s", fpath, err)
+			return fmt.Errorf("Failed to open %s: %s", fpath, err)
+		}
+
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return fmt.Errorf("Failed to write %s to zip: %s", fpath, err)
+		}
+		hdr.Name = fpath
+		hdr.Method = zip.Deflate
+
+		var r io.Reader
+		if fi.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(fpath)
+			if err != nil {
+				return fmt.Errorf("Failed to open %s: %s", fpath, err)
+			}
+			r = strings.NewReader(link)
+		} else {
+			fileToZip, err := os.Open(fpath)
+			if err != nil {
+				return fmt.Errorf("Failed to open %s: %s", fpath, err)
+			}
+			defer func() {
+				_ = fileToZip.Close()
+			}()
+			r = fileToZip
 		}
-		defer func() {
-			_ = fileToZip.Close()
-		}()
 
-		wr, _ := zipw.Create(fpath)
+		wr, err := zipw.CreateHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("Failed to write %s to zip: %s", fpath, err)
+		}
 		keys[fpath] = true
-		if _, err := io.Copy(wr, fileToZip); err != nil {
-			return false, fmt.Errorf("Failed to write %s to zip: %s", fpath, err)
+		if _, err = io.Copy(wr, r); err != nil {
+			return fmt.Errorf("Failed to write %s to zip: %s", fpath, err)
+		}
+	}
+
+	return nil
+}
+
+// zipEndRecord 读取zip的目录结束记录,返回其位置、中央目录的偏移和大小;不支持zip64.
+func zipEndRecord(f *os.File, size int64) (end, cdOffset, cdSize int64, err error) {
+	//目录结束记录为22字节,其后可有最长65535字节的注释
+	bufLen := int64(22 + 65535)
+	if bufLen > size {
+		bufLen = size
+	}
+	buf := make([]byte, bufLen)
+	if _, err = f.ReadAt(buf, size-bufLen); err != nil {
+		return
+	}
+
+	for i := len(buf) - 22; i >= 0; i-- {
+		if !bytes.Equal(buf[i:i+4], []byte("PK\x05\x06")) || i+22+int(binary.LittleEndian.Uint16(buf[i+20:])) != len(buf) {
+			continue
+		}
+		if binary.LittleEndian.Uint16(buf[i+10:]) == 0xffff || binary.LittleEndian.Uint32(buf[i+16:]) == 0xffffffff {
+			err = errors.New("zip64 is not supported")
+			return
 		}
+		end = size - bufLen + int64(i)
+		cdSize = int64(binary.LittleEndian.Uint32(buf[i+12:]))
+		cdOffset = int64(binary.LittleEndian.Uint32(buf[i+16:]))
+		return
+	}
+
+	err = zip.ErrFormat
+	return
+}
+
+// AppendZip 将文件或目录追加到已有的zip文件dst,不重新压缩已有条目;dst不存在时等同Zip.
+// 与已有条目同名的文件会被跳过;追加失败时恢复原有内容;不支持zip前有其他数据的文件,如自解压文件.
+func (kf *LkkFile) AppendZip(dst string, fpaths ...string) (bool, error) {
+	if !kf.IsFile(dst) {
+		return kf.Zip(dst, fpaths...)
+	}
+
+	dst = kf.AbsPath(dst)
+	allfiles := kf.zipCollect(fpaths)
+	if len(allfiles) == 0 {
+		return false, errors.New("No exist files.")
+	}
+
+	f, err := os.OpenFile(dst, os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	zr, err := zip.NewReader(f, stat.Size())
+	if err != nil {
+		return false, err
+	}
+	end, cdOffset, cdSize, err := zipEndRecord(f, stat.Size())
+	if err != nil {
+		return false, err
+	} else if end-cdSize-cdOffset != 0 {
+		//中央目录的实际位置与记录的偏移不一致,说明zip前有其他数据,如自解压文件
+		return false, fmt.Errorf("%s: zip with prefixed data is not supported", dst)
+	}
+
+	//保存原中央目录及之后的内容,用于合并和失败时恢复
+	tail := make([]byte, stat.Size()-cdOffset)
+	if _, err = f.ReadAt(tail, cdOffset); err != nil {
+		return false, err
+	}
+	restore := func(err error) (bool, error) {
+		_ = f.Truncate(cdOffset)
+		_, _ = f.WriteAt(tail, cdOffset)
+		return false, err
+	}
+
+	keys := make(map[string]bool)
+	for _, zf := range zr.File {
+		keys[zf.Name] = true
+	}
+
+	if _, err = f.Seek(cdOffset, io.SeekStart); err != nil {
+		return false, err
+	}
+	zipw := zip.NewWriter(f)
+	zipw.SetOffset(cdOffset)
+	if err = kf.zipWrite(zipw, dst, allfiles, keys); err != nil {
+		return restore(err)
+	}
+
+	//Close在新条目之后写入只含新条目的中央目录和目录结束记录
+	if err = zipw.Close(); err != nil {
+		return restore(err)
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return restore(err)
+	}
+	newEnd, pos, newCdSize, err := zipEndRecord(f, size)
+	if err != nil {
+		return restore(err)
+	}
+	newCd := make([]byte, newCdSize+22)
+	if _, err = f.ReadAt(newCd, pos); err != nil || newEnd != pos+newCdSize {
+		return restore(zip.ErrFormat)
+	}
+
+	//合并原有和新的中央目录,保留原注释
+	oldEnd := tail[end-cdOffset:]
+	count := int(binary.LittleEndian.Uint16(oldEnd[10:])) + int(binary.LittleEndian.Uint16(newCd[newCdSize+10:]))
+	newCd = newCd[:newCdSize]
+	if count > 0xffff || pos+cdSize+newCdSize > 0xffffffff {
+		return restore(errors.New("zip64 is not supported"))
+	}
+
+	buf := make([]byte, 0, cdSize+newCdSize+int64(len(oldEnd)))
+	buf = append(buf, tail[:cdSize]...)
+	buf = append(buf, newCd...)
+	record := make([]byte, len(oldEnd))
+	copy(record, oldEnd)
+	binary.LittleEndian.PutUint16(record[8:], uint16(count))
+	binary.LittleEndian.PutUint16(record[10:], uint16(count))
+	binary.LittleEndian.PutUint32(record[12:], uint32(cdSize+newCdSize))
+	binary.LittleEndian.PutUint32(record[16:], uint32(pos))
+	buf = append(buf, record...)
+
+	if err = f.Truncate(pos); err != nil {
+		return restore(err)
+	} else if _, err = f.WriteAt(buf, pos); err != nil {
+		return restore(err)
+	} else if err = f.Sync(); err != nil {
+		return false, err
 	}
 
 	return true, nil
 }
 
 // UnZip 解压zip文件.srcZip为zip文件路径,dstDir为解压目录.
-func (kf *LkkFile) UnZip(srcZip, dstDir string) (bool, error) {
+// 条目路径不会超出dstDir;opts为解压选项,可限制符号链接、总字节数、文件数和压缩比,未指定时不解压符号链接并覆盖已存在的文件.
+// 条目出错时返回*ExtractError,此前已解压的文件会保留.
+func (kf *LkkFile) UnZip(srcZip, dstDir string, opts ...ExtractOptions) (bool, error) {
 	reader, err := zip.OpenReader(srcZip)
 	if err != nil {
 		return false, err
@@ -1122,43 +2272,408 @@ func (kf *LkkFile) UnZip(srcZip, dstDir string) (bool, error) {
 		_ = reader.Close()
 	}()
 
-	dstDir = strings.TrimRight(kf.AbsPath(dstDir), "/\\")
-	if !kf.IsExist(dstDir) {
-		err := kf.Mkdir(dstDir, os.ModePerm)
+	stat, err := os.Stat(srcZip)
+	if err != nil {
+		return false, err
+	}
+
+	ex, err := newArchiveExtractor(dstDir, stat.Size(), opts)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range reader.File {
+		if err = ex.extractZip(f); err != nil {
+			return false, &ExtractError{Entry: f.Name, Err: err}
+		}
+	}
+
+	return true, nil
+}
+
+// archiveFormat 识别压缩包格式,返回zip、tar、tar.gz或tar.bz2;除扩展名外也根据文件头识别.
+func (kf *LkkFile) archiveFormat(fpath string) (string, error) {
+	if kf.IsZip(fpath) {
+		return "zip", nil
+	}
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	//tar的ustar标识位于257字节处
+	buf := make([]byte, 263)
+	n, _ := io.ReadFull(f, buf)
+	buf = buf[:n]
+	switch {
+	case bytes.HasPrefix(buf, []byte("PK\x03\x04")) || bytes.HasPrefix(buf, []byte("PK\x05\x06")):
+		return "zip", nil
+	case bytes.HasPrefix(buf, []byte{0x1f, 0x8b}):
+		return "tar.gz", nil
+	case bytes.HasPrefix(buf, []byte("BZh")):
+		return "tar.bz2", nil
+	case n >= 262 && bytes.Equal(buf[257:262], []byte("ustar")):
+		return "tar", nil
+	}
+
+	return "", fmt.Errorf("%s: unsupported archive format", fpath)
+}
+
+// openTar 打开tar格式的压缩包,format为tar、tar.gz或tar.bz2;返回tar读取器及关闭时需要调用的Closer.
+func openTar(fpath, format string) (*tar.Reader, *archiveReadCloser, error) {
+	fr, err := os.Open(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rc := &archiveReadCloser{closers: []io.Closer{fr}}
+	var r io.Reader = fr
+	switch format {
+	case "tar.gz":
+		gr, err := gzip.NewReader(fr)
+		if err != nil {
+			_ = fr.Close()
+			return nil, nil, err
+		}
+		rc.closers = append([]io.Closer{gr}, rc.closers...)
+		r = gr
+	case "tar.bz2":
+		r = bzip2.NewReader(fr)
+	}
+
+	tr := tar.NewReader(r)
+	rc.Reader = tr
+	return tr, rc, nil
+}
+
+// Close 依次关闭各层读取器.
+func (rc *archiveReadCloser) Close() (err error) {
+	for _, c := range rc.closers {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return
+}
+
+// ListArchive 列出zip、tar、tar.gz或tar.bz2压缩包中的条目,不解压.
+func (kf *LkkFile) ListArchive(fpath string) ([]ArchiveEntry, error) {
+	format, err := kf.archiveFormat(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []ArchiveEntry
+	if format == "zip" {
+		reader, err := zip.OpenReader(fpath)
 		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		for _, f := range reader.File {
+			info := f.FileInfo()
+			res = append(res, ArchiveEntry{
+				Name:           f.Name,
+				Size:           int64(f.UncompressedSize64),
+				CompressedSize: int64(f.CompressedSize64),
+				Mode:           info.Mode(),
+				ModTime:        info.ModTime(),
+			})
+		}
+		return res, nil
+	}
+
+	tr, rc, err := openTar(fpath, format)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		res = append(res, ArchiveEntry{
+			Name:           hdr.Name,
+			Size:           hdr.Size,
+			CompressedSize: -1,
+			Mode:           hdr.FileInfo().Mode(),
+			ModTime:        hdr.ModTime,
+			Linkname:       hdr.Linkname,
+		})
+	}
+
+	return res, nil
+}
+
+// matchEntry 条目名称是否匹配任一glob模式,模式为目录时匹配其下的所有条目;无模式时全部匹配.
+func matchEntry(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+
+	name = cleanEntryName(name)
+	for _, pattern := range patterns {
+		pattern = cleanEntryName(pattern)
+		if ok, err := path.Match(pattern, name); err != nil {
 			return false, err
+		} else if ok || strings.HasPrefix(name, pattern+"/") {
+			return true, nil
 		}
 	}
 
-	// 迭代压缩文件中的文件
-	for _, f := range reader.File {
-		// Create diretory before create file
-		newPath := dstDir + "/" + strings.TrimLeft(f.Name, "/\\")
-		parentDir := path.Dir(newPath)
-		if !kf.IsExist(parentDir) {
-			err := os.MkdirAll(parentDir, os.ModePerm)
-			if err != nil {
-				return false, err
+	return false, nil
+}
+
+// ExtractFiles 从zip、tar、tar.gz或tar.bz2压缩包中解压名称匹配glob模式的条目到dst,返回已解压的条目名称.
+// patterns如"docs/*.md",为目录时解压其下的所有条目,为空时解压全部;与UnZip相同,条目路径不会超出dst且不解压符号链接.
+// 不限制解压的字节数和文件数,解压不可信的压缩包时应使用ExtractFilesWith.
+func (kf *LkkFile) ExtractFiles(archive, dst string, patterns ...string) ([]string, error) {
+	return kf.extractFiles(archive, dst, nil, patterns)
+}
+
+// ExtractFilesWith 同ExtractFiles,但按opts限制符号链接、总字节数、文件数和压缩比,以及处理已存在的文件.
+func (kf *LkkFile) ExtractFilesWith(archive, dst string, opts ExtractOptions, patterns ...string) ([]string, error) {
+	return kf.extractFiles(archive, dst, []ExtractOptions{opts}, patterns)
+}
+
+// extractFiles 解压压缩包中匹配patterns的条目,opts为空时使用默认选项.
+func (kf *LkkFile) extractFiles(archive, dst string, opts []ExtractOptions, patterns []string) ([]string, error) {
+	format, err := kf.archiveFormat(archive)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(archive)
+	if err != nil {
+		return nil, err
+	}
+	ex, err := newArchiveExtractor(dst, stat.Size(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	extract := func(name string, fn func() error) error {
+		ok, err := matchEntry(name, patterns)
+		if err != nil {
+			return err
+		} else if ok {
+			if err = fn(); err != nil {
+				return &ExtractError{Entry: name, Err: err}
 			}
+			res = append(res, name)
 		}
+		return nil
+	}
 
-		if !f.FileInfo().IsDir() {
-			if fcreate, err := os.Create(newPath); err == nil {
-				if rc, err := f.Open(); err == nil {
-					_, _ = io.Copy(fcreate, rc)
-					_ = rc.Close() //不要用defer来关闭，如果文件太多的话，会报too many open files 的错误
-					_ = fcreate.Close()
-				} else {
-					_ = fcreate.Close()
-					return false, err
-				}
-			} else {
-				return false, err
+	if format == "zip" {
+		reader, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		for _, f := range reader.File {
+			f := f
+			if err = extract(f.Name, func() error { return ex.extractZip(f) }); err != nil {
+				return res, err
 			}
 		}
+		return res, nil
 	}
 
-	return true, nil
+	tr, rc, err := openTar(archive, format)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return res, err
+		}
+
+		if err = extract(hdr.Name, func() error { return ex.extractTar(hdr, tr) }); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// ReadArchiveFile 打开zip、tar、tar.gz或tar.bz2压缩包中名为name的文件用于读取,不解压到磁盘;使用完须调用Close.
+// 条目不存在时返回的错误满足errors.Is(err, os.ErrNotExist).
+func (kf *LkkFile) ReadArchiveFile(archive, name string) (io.ReadCloser, error) {
+	format, err := kf.archiveFormat(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	name = cleanEntryName(name)
+	if format == "zip" {
+		reader, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range reader.File {
+			if cleanEntryName(f.Name) != name || f.FileInfo().IsDir() {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				_ = reader.Close()
+				return nil, err
+			}
+			return &archiveReadCloser{Reader: r, closers: []io.Closer{r, reader}}, nil
+		}
+
+		_ = reader.Close()
+		return nil, fmt.Errorf("[ReadArchiveFile] %s: %w", name, os.ErrNotExist)
+	}
+
+	tr, rc, err := openTar(archive, format)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			_ = rc.Close()
+			return nil, err
+		}
+
+		if cleanEntryName(hdr.Name) == name && (hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA) {
+			return rc, nil
+		}
+	}
+
+	_ = rc.Close()
+	return nil, fmt.Errorf("[ReadArchiveFile] %s: %w", name, os.ErrNotExist)
+}
+
+// transformFile 将src经fn处理后原子地写入dst,失败时不会留下不完整的dst.
+func (kf *LkkFile) transformFile(src, dst string, fn func(w io.Writer, r io.Reader) error) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = srcFile.Close()
+	}()
+
+	stat, err := srcFile.Stat()
+	if err != nil {
+		return err
+	} else if !stat.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+
+	aw, err := kf.NewAtomicWriter(dst, stat.Mode().Perm(), false)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriterSize(aw, 65536)
+	if err = fn(bw, srcFile); err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		_ = aw.Abort()
+		return err
+	}
+
+	return aw.Close()
+}
+
+// EncryptFile 将文件src分块认证加密后写入dst,不会将整个文件读入内存.
+// key为任意长度的密钥;使用AES-256-GCM,格式见KEncr.NewEncryptWriter.
+func (kf *LkkFile) EncryptFile(src, dst string, key []byte) error {
+	return kf.transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		_, err := KEncr.EncryptStream(w, r, key)
+		return err
+	})
+}
+
+// DecryptFile 将EncryptFile加密的文件src解密后写入dst.
+// 密文被篡改、块顺序被调换或文件被截断时返回错误,且不会生成dst.
+func (kf *LkkFile) DecryptFile(src, dst string, key []byte) error {
+	return kf.transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		_, err := KEncr.DecryptStream(w, r, key)
+		return err
+	})
+}
+
+// Gzip 将单个文件src压缩为gzip文件dst;level为压缩级别,默认gzip.DefaultCompression.
+// 文件名和修改时间记录在gzip头部.
+func (kf *LkkFile) Gzip(src, dst string, level ...int) error {
+	lev := gzip.DefaultCompression
+	if len(level) > 0 {
+		lev = level[0]
+	}
+
+	stat, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	return kf.transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		gw, err := gzip.NewWriterLevel(w, lev)
+		if err != nil {
+			return err
+		}
+		gw.Name = filepath.Base(src)
+		gw.ModTime = stat.ModTime()
+		if _, err = io.Copy(gw, r); err != nil {
+			return err
+		}
+		return gw.Close()
+	})
+}
+
+// Gunzip 将gzip文件src解压到dst,并恢复gzip头部记录的修改时间.
+func (kf *LkkFile) Gunzip(src, dst string) error {
+	var mtime time.Time
+	err := kf.transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		mtime = gr.ModTime
+		if _, err = io.Copy(w, gr); err != nil {
+			return err
+		}
+		return gr.Close()
+	})
+	if err == nil && !mtime.IsZero() {
+		err = os.Chtimes(dst, mtime, mtime)
+	}
+
+	return err
 }
 
 // IsZip 是否zip文件.
@@ -1,11 +1,19 @@
 package kgo
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetExt(t *testing.T) {
@@ -65,6 +73,110 @@ func BenchmarkWriteFile(b *testing.B) {
 	}
 }
 
+func TestWriteFileAtomic(t *testing.T) {
+	//清理上次运行留下的文件
+	_ = os.RemoveAll("./testdata/atomic")
+	fpath := "./testdata/atomic/state.json"
+	err := KFile.WriteFileAtomic(fpath, []byte(`{"v":1}`), 0600)
+	data, _ := KFile.ReadFile(fpath)
+	mode, _ := KFile.GetFileMode(fpath)
+	if err != nil || string(data) != `{"v":1}` || mode.Perm() != 0600 {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+
+	//沿用已有文件的权限,并保留旧文件
+	err = KFile.WriteFileAtomic(fpath, []byte(`{"v":2}`), 0, true)
+	data, _ = KFile.ReadFile(fpath)
+	bak, _ := KFile.ReadFile(fpath + ".bak")
+	mode, _ = KFile.GetFileMode(fpath)
+	if err != nil || string(data) != `{"v":2}` || string(bak) != `{"v":1}` || mode.Perm() != 0600 {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+
+	_ = KFile.WriteFileAtomic(fpath, []byte(`{"v":3}`), 0, true)
+	bak, _ = KFile.ReadFile(fpath + ".bak")
+	if string(bak) != `{"v":2}` {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+
+	//不留下临时文件
+	files := KFile.FileTree("./testdata/atomic", FILE_TREE_FILE, false)
+	if len(files) != 2 {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+
+	err = KFile.WriteFileAtomic("/root/atomic/state.json", data, 0)
+	if err == nil {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+	err = KFile.WriteFileAtomic("./testdata/atomic", data, 0, true)
+	if err == nil {
+		t.Error("WriteFileAtomic fail")
+		return
+	}
+}
+
+func BenchmarkWriteFileAtomic(b *testing.B) {
+	b.ResetTimer()
+	str := []byte("Hello World!")
+	for i := 0; i < b.N; i++ {
+		_ = KFile.WriteFileAtomic("./testdata/atomic/bench", str, 0644)
+	}
+}
+
+func TestAtomicWriter(t *testing.T) {
+	fpath := "./testdata/atomic/stream.txt"
+	_ = KFile.WriteFile(fpath, []byte("old"))
+	aw, err := KFile.NewAtomicWriter(fpath, 0644, false)
+	if err != nil {
+		t.Error("NewAtomicWriter fail")
+		return
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = aw.Write([]byte("hello "))
+	}
+	//关闭前目标文件不变
+	data, _ := KFile.ReadFile(fpath)
+	if string(data) != "old" {
+		t.Error("AtomicWriter fail")
+		return
+	}
+	err = aw.Close()
+	data, _ = KFile.ReadFile(fpath)
+	if err != nil || string(data) != "hello hello hello " || KFile.IsExist(fpath+".bak") {
+		t.Error("AtomicWriter fail")
+		return
+	}
+
+	_, err = aw.Write([]byte("hello"))
+	if err != os.ErrClosed || aw.Close() != os.ErrClosed || aw.Abort() != os.ErrClosed {
+		t.Error("AtomicWriter fail")
+		return
+	}
+
+	//放弃写入
+	aw, _ = KFile.NewAtomicWriter(fpath, 0, true)
+	_, _ = aw.Write([]byte("world"))
+	err = aw.Abort()
+	data, _ = KFile.ReadFile(fpath)
+	if err != nil || string(data) != "hello hello hello " || KFile.IsExist(fpath+".bak") {
+		t.Error("AtomicWriter fail")
+		return
+	}
+
+	_, err = KFile.NewAtomicWriter("/root/atomic/stream.txt", 0, false)
+	if err == nil {
+		t.Error("NewAtomicWriter fail")
+		return
+	}
+}
+
 func TestGetMime(t *testing.T) {
 	filename := "./testdata/diglett.png"
 	mime1 := KFile.GetMime(filename, true)
@@ -78,6 +190,128 @@ func TestGetMime(t *testing.T) {
 	KFile.GetMime("./", false)
 }
 
+// newTestZipBytes 生成包含给定条目的zip内容.
+func newTestZipBytes(entries ...testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, _ := zw.Create(entry.name)
+		_, _ = w.Write([]byte(entry.body))
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestDetectFileType(t *testing.T) {
+	pe := make([]byte, 128)
+	copy(pe, "MZ")
+	pe[60] = 64
+	copy(pe[64:], "PE\x00\x00")
+	tarHead := make([]byte, 512)
+	copy(tarHead[257:], "ustar")
+
+	var tests = []struct {
+		param    []byte
+		ext      string
+		category LkkFileCategory
+	}{
+		{[]byte("RIFF\x24\x00\x00\x00WEBPVP8 "), "webp", FILE_CATEGORY_IMAGE},
+		{[]byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), "heic", FILE_CATEGORY_IMAGE},
+		{[]byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf"), "avif", FILE_CATEGORY_IMAGE},
+		{[]byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1avif"), "avif", FILE_CATEGORY_IMAGE},
+		{[]byte("<?xml version=\"1.0\"?>\n<!-- logo -->\n<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), "svg", FILE_CATEGORY_IMAGE},
+		{[]byte("BM\x36\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00"), "bmp", FILE_CATEGORY_IMAGE},
+		{[]byte("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00isomiso2avc1mp41"), "mp4", FILE_CATEGORY_VIDEO},
+		{[]byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00qt  "), "mov", FILE_CATEGORY_VIDEO},
+		{[]byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x88matroska"), "mkv", FILE_CATEGORY_VIDEO},
+		{[]byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm"), "webm", FILE_CATEGORY_VIDEO},
+		{[]byte("fLaC\x00\x00\x00\x22"), "flac", FILE_CATEGORY_AUDIO},
+		{[]byte("ID3\x04\x00\x00"), "mp3", FILE_CATEGORY_AUDIO},
+		{[]byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00M4A "), "m4a", FILE_CATEGORY_AUDIO},
+		{[]byte("7z\xbc\xaf\x27\x1c\x00\x04"), "7z", FILE_CATEGORY_ARCHIVE},
+		{[]byte("Rar!\x1a\x07\x01\x00"), "rar", FILE_CATEGORY_ARCHIVE},
+		{[]byte("\xfd7zXZ\x00\x00\x04"), "xz", FILE_CATEGORY_ARCHIVE},
+		{[]byte("\x28\xb5\x2f\xfd\x24\x05"), "zst", FILE_CATEGORY_ARCHIVE},
+		{tarHead, "tar", FILE_CATEGORY_ARCHIVE},
+		{newTestZipBytes(testArchiveEntry{name: "a.txt", body: "a"}), "zip", FILE_CATEGORY_ARCHIVE},
+		{newTestZipBytes(testArchiveEntry{name: "[Content_Types].xml"}, testArchiveEntry{name: "word/document.xml"}), "docx", FILE_CATEGORY_DOCUMENT},
+		{newTestZipBytes(testArchiveEntry{name: "[Content_Types].xml"}, testArchiveEntry{name: "xl/workbook.xml"}), "xlsx", FILE_CATEGORY_DOCUMENT},
+		{newTestZipBytes(testArchiveEntry{name: "mimetype", body: "application/vnd.oasis.opendocument.text"}), "odt", FILE_CATEGORY_DOCUMENT},
+		{newTestZipBytes(testArchiveEntry{name: "META-INF/MANIFEST.MF"}), "jar", FILE_CATEGORY_ARCHIVE},
+		{[]byte("%PDF-1.7\n"), "pdf", FILE_CATEGORY_DOCUMENT},
+		{[]byte("\x7fELF\x02\x01\x01\x00"), "elf", FILE_CATEGORY_EXECUTABLE},
+		{pe, "exe", FILE_CATEGORY_EXECUTABLE},
+		{[]byte("\x00asm\x01\x00\x00\x00"), "wasm", FILE_CATEGORY_EXECUTABLE},
+		{[]byte("\xca\xfe\xba\xbe\x00\x00\x00\x34"), "class", FILE_CATEGORY_EXECUTABLE},
+		{[]byte("\xca\xfe\xba\xbe\x00\x00\x00\x02"), "macho", FILE_CATEGORY_EXECUTABLE},
+		{[]byte("wOF2\x00\x01\x00\x00"), "woff2", FILE_CATEGORY_FONT},
+		{[]byte("\x00\x01\x00\x00\x00\x0f\x00\x80"), "ttf", FILE_CATEGORY_FONT},
+		{[]byte("\x00\x00\x00\x18ftypcrx \x00\x00\x00\x01crx isom"), "", FILE_CATEGORY_UNKNOWN},
+		{[]byte("hello world"), "", FILE_CATEGORY_UNKNOWN},
+		{[]byte("BM hello world"), "", FILE_CATEGORY_UNKNOWN},
+		{[]byte("MZ hello world"), "", FILE_CATEGORY_UNKNOWN},
+		{nil, "", FILE_CATEGORY_UNKNOWN},
+	}
+	for _, test := range tests {
+		res, err := KFile.DetectFileType(test.param)
+		if err != nil || res.Ext != test.ext || res.Category != test.category || res.Mime == "" {
+			t.Errorf("Expected DetectFileType(%q) to be %s, got %+v", test.param, test.ext, res)
+			return
+		}
+	}
+
+	var files = []struct {
+		param string
+		ext   string
+		mime  string
+	}{
+		{"./testdata/diglett.png", "png", "image/png"},
+		{"./testdata/gopher10th-small.jpg", "jpg", "image/jpeg"},
+		{"./testdata/jetbrains.svg", "svg", "image/svg+xml"},
+		{"./testdata/perm.tar.bz2", "bz2", "application/x-bzip2"},
+	}
+	for _, test := range files {
+		res, err := KFile.DetectFileType(test.param)
+		if err != nil || res.Ext != test.ext || res.Mime != test.mime {
+			t.Errorf("Expected DetectFileType(%s) to be %s, got %+v", test.param, test.ext, res)
+			return
+		}
+	}
+
+	//根据中央目录识别zip文件
+	_ = os.MkdirAll("./testdata/archive", 0755)
+	_ = ioutil.WriteFile("./testdata/archive/detect.bin", newTestZipBytes(testArchiveEntry{name: "ppt/presentation.xml"}), 0644)
+	res, err := KFile.DetectFileType("./testdata/archive/detect.bin")
+	if err != nil || res.Ext != "pptx" || res.Category != FILE_CATEGORY_DOCUMENT {
+		t.Error("DetectFileType fail")
+		return
+	}
+
+	_, err = KFile.DetectFileType("./testdata/archive/none.bin")
+	if err == nil {
+		t.Error("DetectFileType fail")
+		return
+	}
+	_, err = KFile.DetectFileType("./testdata")
+	if err == nil {
+		t.Error("DetectFileType fail")
+		return
+	}
+	_, err = KFile.DetectFileType(123)
+	if err == nil {
+		t.Error("DetectFileType fail")
+		return
+	}
+}
+
+func BenchmarkDetectFileType(b *testing.B) {
+	b.ResetTimer()
+	filename := "./testdata/diglett.png"
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.DetectFileType(filename)
+	}
+}
+
 func BenchmarkGetMimeFast(b *testing.B) {
 	b.ResetTimer()
 	filename := "./testdata/diglett.png"
@@ -301,6 +535,51 @@ func TestIsImg(t *testing.T) {
 		return
 	}
 	KFile.IsImg("./hello")
+
+	//严格模式检查文件内容
+	_ = os.MkdirAll("./testdata/archive", 0755)
+	_, _ = KFile.CopyFile("./testdata/dante.txt", "./testdata/archive/fake.png", FILE_COVER_ALLOW)
+	if !KFile.IsImg("./testdata/archive/fake.png") || KFile.IsImg("./testdata/archive/fake.png", true) {
+		t.Error("IsImg fail")
+		return
+	}
+	if !KFile.IsImg("./testdata/gopher10th-small.jpg", true) {
+		t.Error("IsImg fail")
+		return
+	}
+
+	//svg可包含脚本,严格模式下不视为图片
+	if !KFile.IsImg("./testdata/jetbrains.svg") || KFile.IsImg("./testdata/jetbrains.svg", true) {
+		t.Error("IsImg fail")
+		return
+	}
+	if KFile.IsImg("./hello", true) {
+		t.Error("IsImg fail")
+		return
+	}
+}
+
+func TestIsSvg(t *testing.T) {
+	if !KFile.IsSvg("./testdata/jetbrains.svg") || KFile.IsSvg("./testdata/diglett.png") || KFile.IsSvg("./hello") {
+		t.Error("IsSvg fail")
+		return
+	}
+}
+
+func BenchmarkIsSvg(b *testing.B) {
+	b.ResetTimer()
+	filename := "./testdata/jetbrains.svg"
+	for i := 0; i < b.N; i++ {
+		KFile.IsSvg(filename)
+	}
+}
+
+func BenchmarkIsImgStrict(b *testing.B) {
+	b.ResetTimer()
+	filename := "./testdata/diglett.png"
+	for i := 0; i < b.N; i++ {
+		KFile.IsImg(filename, true)
+	}
 }
 
 func BenchmarkIsImg(b *testing.B) {
@@ -1104,7 +1383,7 @@ func TestZipIszipUnzip(t *testing.T) {
 	cmd := exec.Command("/bin/bash", "-c", "ln -sf ./testdata/jetbrains.svg-bak ./testdata/svg-lnk")
 	_ = cmd.Run()
 	_, err = KFile.Zip(zfile, "./testdata")
-	if err == nil {
+	if err != nil {
 		t.Error("Zip fail")
 		return
 	}
@@ -1145,138 +1424,1086 @@ func TestZipIszipUnzip(t *testing.T) {
 	}
 }
 
-func BenchmarkZip(b *testing.B) {
-	b.ResetTimer()
-	src := "./README.md"
-	for i := 0; i < b.N; i++ {
-		dst := fmt.Sprintf("./zip/test_%d.zip", i)
-		_, _ = KFile.Zip(dst, src)
+// testArchiveEntry 测试压缩包的条目
+type testArchiveEntry struct {
+	name string // 名称
+	body string // 内容
+	link string // 链接目标,非空时为符号链接
+	hard bool   // 是否为硬链接
+}
+
+func newTestTarGz(fpath string, entries ...testArchiveEntry) error {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.hard {
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e.link, 0
+		} else if e.link != "" {
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		} else if strings.HasSuffix(e.name, "/") {
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Size > 0 {
+			_, _ = tw.Write([]byte(e.body))
+		}
 	}
+	_ = tw.Close()
+	_ = gw.Close()
+
+	return KFile.WriteFile(fpath, buf.Bytes(), 0644)
 }
 
-func BenchmarkUnzip(b *testing.B) {
-	b.ResetTimer()
-	var src, dst string
-	for i := 0; i < b.N; i++ {
-		src = fmt.Sprintf("./zip/test_%d.zip", i)
-		dst = fmt.Sprintf("./zip/unzip/test_%d", i)
-		_, _ = KFile.UnZip(src, dst)
+func newTestZip(fpath string, entries ...testArchiveEntry) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		if e.link != "" {
+			hdr.SetMode(os.ModeSymlink | 0777)
+			body = e.link
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, _ = w.Write([]byte(body))
 	}
+	_ = zw.Close()
+
+	return KFile.WriteFile(fpath, buf.Bytes(), 0644)
 }
 
-func TestGetFileMode(t *testing.T) {
-	filepath := "./testdata/dante.txt"
-	perm, err := KFile.GetFileMode(filepath)
-	if err != nil || perm <= 0 {
-		t.Error("GetFileMode fail")
+func TestUnZipUnsafe(t *testing.T) {
+	src := "./testdata/extract/unsafe.zip"
+	dst := "./testdata/extract/unsafe"
+	_ = os.RemoveAll(dst)
+	_ = newTestZip(src, testArchiveEntry{name: "a.txt", body: "a"}, testArchiveEntry{name: "../evil.txt", body: "evil"})
+	_, err := KFile.UnZip(src, dst)
+	var ee *ExtractError
+	if !errors.As(err, &ee) || ee.Entry != "../evil.txt" || !errors.Is(err, ErrUnsafePath) || KFile.IsExist("./testdata/extract/evil.txt") {
+		t.Error("UnZip fail")
+		return
+	}
+	if !strings.Contains(err.Error(), "../evil.txt") {
+		t.Error("ExtractError fail")
 		return
 	}
 
-	perm, err = KFile.GetFileMode("/root/hello/world")
-	if err == nil || perm != 0 {
-		t.Error("GetFileMode fail")
+	//反斜杠分隔符
+	_ = newTestZip(src, testArchiveEntry{name: "..\\evil.txt", body: "evil"})
+	_, err = KFile.UnZip(src, dst)
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Error("UnZip fail")
 		return
 	}
-}
 
-func BenchmarkGetFileMode(b *testing.B) {
-	b.ResetTimer()
-	filepath := "./testdata/dante.txt"
-	for i := 0; i < b.N; i++ {
-		_, _ = KFile.GetFileMode(filepath)
+	//符号链接:默认跳过,仅允许内部链接,或全部允许
+	_ = newTestZip(src, testArchiveEntry{name: "a.txt", body: "a"}, testArchiveEntry{name: "in", link: "a.txt"}, testArchiveEntry{name: "out", link: "/etc/passwd"})
+	_, err = KFile.UnZip(src, dst+"/skip")
+	if err != nil || KFile.IsExist(dst+"/skip/in") || KFile.IsExist(dst+"/skip/out") {
+		t.Error("UnZip fail")
+		return
+	}
+	_, err = KFile.UnZip(src, dst+"/internal", ExtractOptions{Symlink: SYMLINK_INTERNAL})
+	if !errors.Is(err, ErrUnsafeLink) || !KFile.IsLink(dst+"/internal/in") || KFile.IsExist(dst+"/internal/out") {
+		t.Error("UnZip fail")
+		return
+	}
+	_, err = KFile.UnZip(src, dst+"/allow", ExtractOptions{Symlink: SYMLINK_ALLOW})
+	if err != nil || !KFile.IsLink(dst+"/allow/out") {
+		t.Error("UnZip fail")
+		return
 	}
 }
 
-func TestAppendFile(t *testing.T) {
-	cont := []byte("hello world.")
-	err := KFile.AppendFile("", cont)
-	if err == nil {
-		t.Error("AppendFile fail")
+func TestUnTarGzUnsafe(t *testing.T) {
+	src := "./testdata/extract/unsafe.tar.gz"
+	dst := "./testdata/extract/untar"
+	opts := ExtractOptions{Symlink: SYMLINK_ALLOW}
+	_ = os.RemoveAll(dst)
+
+	//绝对路径视为相对于解压目录
+	_ = newTestTarGz(src, testArchiveEntry{name: "dir/"}, testArchiveEntry{name: "/abs.txt", body: "abs"})
+	_, err := KFile.UnTarGz(src, dst)
+	if err != nil || !KFile.IsDir(dst+"/dir") || !KFile.IsFile(dst+"/abs.txt") {
+		t.Error("UnTarGz fail")
 		return
 	}
 
-	pth := "./testdata/append.txt"
-	err = KFile.AppendFile(pth, cont)
-	if err != nil {
-		t.Error("AppendFile fail")
+	//经由指向外部的符号链接写入
+	_ = newTestTarGz(src, testArchiveEntry{name: "link", link: "/tmp"}, testArchiveEntry{name: "link/kgo-evil.txt", body: "evil"})
+	_, err = KFile.UnTarGz(src, dst, opts)
+	var ee *ExtractError
+	if !errors.As(err, &ee) || ee.Entry != "link/kgo-evil.txt" || !errors.Is(err, ErrUnsafePath) || KFile.IsExist("/tmp/kgo-evil.txt") {
+		t.Error("UnTarGz fail")
 		return
 	}
 
-	err = KFile.AppendFile(pth, []byte("how are you?"))
-	if err != nil {
-		t.Error("AppendFile fail")
+	//链接到已链接的目录再跳出
+	_ = newTestTarGz(src, testArchiveEntry{name: "self", link: "."}, testArchiveEntry{name: "self/up", link: "../outside"})
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{Symlink: SYMLINK_INTERNAL})
+	if !errors.Is(err, ErrUnsafeLink) {
+		t.Error("UnTarGz fail")
 		return
 	}
 
-	_ = KFile.AppendFile("/root/hello/world", []byte("how are you?"))
-	_ = KFile.AppendFile(pth, []byte(""))
-}
+	//经由已解压的链接层层跳出
+	_ = newTestTarGz(src, testArchiveEntry{name: "b", link: "."}, testArchiveEntry{name: "a", link: "b/b/b/b/b/../../../../../etc/passwd"})
+	_, err = KFile.UnTarGz(src, dst+"/nested", ExtractOptions{Symlink: SYMLINK_INTERNAL})
+	if !errors.Is(err, ErrUnsafeLink) || KFile.IsExist(dst+"/nested/a") {
+		t.Error("UnTarGz fail")
+		return
+	}
 
-func BenchmarkAppendFile(b *testing.B) {
-	b.ResetTimer()
-	pth := "./testdata/append.txt"
-	cont := []byte("hello world.\r\n")
-	for i := 0; i < b.N; i++ {
-		_ = KFile.AppendFile(pth, cont)
+	//已存在的链接指向外部
+	_ = os.MkdirAll(dst+"/exist", 0755)
+	_ = os.Remove(dst + "/exist/out")
+	_ = os.Symlink("/etc", dst+"/exist/out")
+	_ = newTestTarGz(src, testArchiveEntry{name: "passwd", link: "out/passwd"})
+	_, err = KFile.UnTarGz(src, dst+"/exist", ExtractOptions{Symlink: SYMLINK_INTERNAL})
+	if !errors.Is(err, ErrUnsafeLink) {
+		t.Error("UnTarGz fail")
+		return
 	}
-}
 
-func TestReadFirstLine(t *testing.T) {
-	var tests = []struct {
-		file     string
-		expected string
-	}{
-		{"", ""},
-		{"./testdata/firstline.log", ""},
-		{"./testdata/dante.txt", "LA DIVINA COMMEDIA"},
-		{"docs/changelog.md", "# Changelog"},
+	//链接到尚未解压的文件及开头的".."
+	_ = newTestTarGz(src, testArchiveEntry{name: "sub/up", link: "../later.txt"}, testArchiveEntry{name: "later.txt", body: "later"})
+	_, err = KFile.UnTarGz(src, dst+"/later", ExtractOptions{Symlink: SYMLINK_INTERNAL})
+	data, _ := KFile.ReadFile(dst + "/later/sub/up")
+	if err != nil || string(data) != "later" {
+		t.Error("UnTarGz fail")
+		return
 	}
-	for _, test := range tests {
-		actual := KFile.ReadFirstLine(test.file)
-		if actual != test.expected {
-			t.Errorf("Expected FirstLine(%q) to be %v, got %v", test.file, test.expected, actual)
-		}
+
+	//硬链接
+	_ = newTestTarGz(src, testArchiveEntry{name: "a.txt", body: "a"}, testArchiveEntry{name: "b.txt", link: "a.txt", hard: true})
+	_, err = KFile.UnTarGz(src, dst)
+	data, _ = KFile.ReadFile(dst + "/b.txt")
+	if err != nil || string(data) != "a" {
+		t.Error("UnTarGz fail")
+		return
+	}
+	_ = newTestTarGz(src, testArchiveEntry{name: "c.txt", link: "../../../file.go", hard: true})
+	_, err = KFile.UnTarGz(src, dst)
+	if !errors.Is(err, ErrUnsafeLink) {
+		t.Error("UnTarGz fail")
+		return
 	}
-}
 
-func BenchmarkReadFirstLine(b *testing.B) {
-	b.ResetTimer()
-	fpath := "./testdata/dante.txt"
-	for i := 0; i < b.N; i++ {
-		KFile.ReadFirstLine(fpath)
+	_ = KFile.WriteFile(src, []byte("not a gzip"))
+	_, err = KFile.UnTarGz(src, dst)
+	if err == nil {
+		t.Error("UnTarGz fail")
+		return
 	}
 }
 
-func TestReadLastLine(t *testing.T) {
-	tfile1 := "./testdata/lastline1.log"
-	tfile2 := "./testdata/lastline2.log"
-	str := "hello World"
-	KFile.Touch(tfile1, 0)
-	_ = KFile.WriteFile(tfile2, []byte(str))
+func TestExtractOptions(t *testing.T) {
+	src := "./testdata/extract/limit.tar.gz"
+	dst := "./testdata/extract/limit"
+	body := strings.Repeat("0", 100000)
+	_ = newTestTarGz(src, testArchiveEntry{name: "a.txt", body: body}, testArchiveEntry{name: "b.txt", body: "b"})
 
-	var tests = []struct {
-		file     string
-		expected string
-	}{
-		{"", ""},
-		{"./testdata/firstline.log", ""},
-		{tfile1, ""},
-		{tfile2, str},
-		{"./testdata/dante.txt", ""},
-		{"docs/changelog.md", "*--end of file--*"},
+	_ = os.RemoveAll(dst)
+	_, err := KFile.UnTarGz(src, dst, ExtractOptions{MaxFiles: 1})
+	if !errors.Is(err, ErrExtractLimit) || KFile.IsExist(dst+"/b.txt") {
+		t.Error("ExtractOptions MaxFiles fail")
+		return
 	}
-	for _, test := range tests {
-		actual := KFile.ReadLastLine(test.file)
-		if KStr.Trim(actual) != test.expected {
-			t.Errorf("Expected FirstLine(%q) to be [%s], got [%s]", test.file, test.expected, actual)
-		}
+	_ = os.RemoveAll(dst)
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{MaxBytes: 1000})
+	if !errors.Is(err, ErrExtractLimit) || KFile.IsExist(dst+"/a.txt") {
+		t.Error("ExtractOptions MaxBytes fail")
+		return
+	}
+	_ = os.RemoveAll(dst)
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{MaxRatio: 10})
+	if !errors.Is(err, ErrExtractLimit) {
+		t.Error("ExtractOptions MaxRatio fail")
+		return
+	}
+	_ = os.RemoveAll(dst)
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{MaxBytes: 100001, MaxFiles: 2, MaxRatio: 1000})
+	if err != nil {
+		t.Error("ExtractOptions fail")
+		return
 	}
-}
 
-func BenchmarkReadLastLine(b *testing.B) {
-	b.ResetTimer()
-	fpath := "./testdata/dante.txt"
-	for i := 0; i < b.N; i++ {
-		KFile.ReadLastLine(fpath)
+	//覆盖策略
+	_ = KFile.WriteFile(dst+"/b.txt", []byte("old"))
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{Existing: EXISTING_DENY})
+	var ee *ExtractError
+	if !errors.As(err, &ee) || !errors.Is(err, os.ErrExist) {
+		t.Error("ExtractOptions Existing fail")
+		return
+	}
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{Existing: EXISTING_SKIP})
+	data, _ := KFile.ReadFile(dst + "/b.txt")
+	if err != nil || string(data) != "old" {
+		t.Error("ExtractOptions Existing fail")
+		return
+	}
+	_, err = KFile.UnTarGz(src, dst)
+	data, _ = KFile.ReadFile(dst + "/b.txt")
+	if err != nil || string(data) != "b" {
+		t.Error("ExtractOptions Existing fail")
+		return
+	}
+	//指定其他选项时仍默认覆盖
+	_ = KFile.WriteFile(dst+"/b.txt", []byte("old"))
+	_, err = KFile.UnTarGz(src, dst, ExtractOptions{MaxFiles: 5})
+	data, _ = KFile.ReadFile(dst + "/b.txt")
+	if err != nil || string(data) != "b" {
+		t.Error("ExtractOptions Existing fail")
+		return
+	}
+
+	//zip
+	zsrc := "./testdata/extract/limit.zip"
+	_ = newTestZip(zsrc, testArchiveEntry{name: "a.txt", body: body})
+	_, err = KFile.UnZip(zsrc, dst+"/zip", ExtractOptions{MaxRatio: 10})
+	if !errors.Is(err, ErrExtractLimit) {
+		t.Error("ExtractOptions MaxRatio fail")
+		return
+	}
+}
+
+func TestListArchive(t *testing.T) {
+	zsrc := "./testdata/archive/list.zip"
+	tsrc := "./testdata/archive/list.tar.gz"
+	entries := []testArchiveEntry{{name: "docs/"}, {name: "docs/a.md", body: "hello"}, {name: "b.txt", body: strings.Repeat("b", 1000)}}
+	_ = newTestZip(zsrc, entries...)
+	_ = newTestTarGz(tsrc, append(entries, testArchiveEntry{name: "lnk", link: "b.txt"})...)
+
+	list, err := KFile.ListArchive(zsrc)
+	if err != nil || len(list) != 3 || list[1].Name != "docs/a.md" || list[1].Size != 5 || !list[0].Mode.IsDir() {
+		t.Error("ListArchive fail")
+		return
+	}
+	if list[2].Size != 1000 || list[2].CompressedSize <= 0 || list[2].CompressedSize >= 1000 {
+		t.Error("ListArchive fail")
+		return
+	}
+
+	list, err = KFile.ListArchive(tsrc)
+	if err != nil || len(list) != 4 || list[2].Size != 1000 || list[2].CompressedSize != -1 || list[2].Mode.Perm() != 0644 {
+		t.Error("ListArchive fail")
+		return
+	}
+	if list[3].Linkname != "b.txt" || list[3].Mode&os.ModeSymlink == 0 || list[0].ModTime.IsZero() && !list[0].Mode.IsDir() {
+		t.Error("ListArchive fail")
+		return
+	}
+
+	//无扩展名时根据文件头识别
+	_, _ = KFile.CopyFile(zsrc, "./testdata/archive/upload.bin", FILE_COVER_ALLOW)
+	list, err = KFile.ListArchive("./testdata/archive/upload.bin")
+	if err != nil || len(list) != 3 {
+		t.Error("ListArchive fail")
+		return
+	}
+
+	_, err = KFile.ListArchive("./testdata/dante.txt")
+	if err == nil {
+		t.Error("ListArchive fail")
+		return
+	}
+	_, err = KFile.ListArchive("./testdata/archive/none.zip")
+	if err == nil {
+		t.Error("ListArchive fail")
+		return
+	}
+}
+
+func BenchmarkListArchive(b *testing.B) {
+	b.ResetTimer()
+	src := "./testdata/archive/bench.zip"
+	_ = newTestZip(src, testArchiveEntry{name: "a.txt", body: "hello"})
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.ListArchive(src)
+	}
+}
+
+func TestExtractFiles(t *testing.T) {
+	zsrc := "./testdata/archive/extract.zip"
+	tsrc := "./testdata/archive/extract.tar.gz"
+	entries := []testArchiveEntry{{name: "docs/a.md", body: "a"}, {name: "docs/sub/b.md", body: "b"}, {name: "c.txt", body: "c"}, {name: "d.md", body: "d"}}
+	_ = newTestZip(zsrc, entries...)
+	_ = newTestTarGz(tsrc, entries...)
+
+	for _, src := range []string{zsrc, tsrc} {
+		dst := "./testdata/archive/out/" + KFile.Basename(src)
+		_ = os.RemoveAll(dst)
+		res, err := KFile.ExtractFiles(src, dst, "*.md")
+		if err != nil || len(res) != 1 || res[0] != "d.md" || KFile.IsExist(dst+"/c.txt") {
+			t.Error("ExtractFiles fail")
+			return
+		}
+
+		res, err = KFile.ExtractFiles(src, dst, "docs", "c.txt")
+		data, _ := KFile.ReadFile(dst + "/docs/sub/b.md")
+		if err != nil || len(res) != 3 || string(data) != "b" {
+			t.Error("ExtractFiles fail")
+			return
+		}
+
+		res, _ = KFile.ExtractFiles(src, dst)
+		if len(res) != 4 {
+			t.Error("ExtractFiles fail")
+			return
+		}
+
+		_, err = KFile.ExtractFiles(src, dst, "[")
+		if err == nil {
+			t.Error("ExtractFiles fail")
+			return
+		}
+	}
+
+	_ = newTestTarGz(tsrc, testArchiveEntry{name: "../evil.md", body: "evil"})
+	_, err := KFile.ExtractFiles(tsrc, "./testdata/archive/out", "*.md", "../*.md")
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Error("ExtractFiles fail")
+		return
+	}
+	_, err = KFile.ExtractFiles("./testdata/dante.txt", "./testdata/archive/out")
+	if err == nil {
+		t.Error("ExtractFiles fail")
+		return
+	}
+}
+
+func TestExtractFilesWith(t *testing.T) {
+	src := "./testdata/archive/with.tar.gz"
+	dst := "./testdata/archive/with"
+	_ = newTestTarGz(src, testArchiveEntry{name: "a.md", body: strings.Repeat("a", 10000)}, testArchiveEntry{name: "b.md", body: "b"}, testArchiveEntry{name: "c.txt", body: "c"})
+	_ = os.RemoveAll(dst)
+
+	_, err := KFile.ExtractFilesWith(src, dst, ExtractOptions{MaxFiles: 1}, "*.md")
+	if !errors.Is(err, ErrExtractLimit) {
+		t.Error("ExtractFilesWith fail")
+		return
+	}
+	_, err = KFile.ExtractFilesWith(src, dst, ExtractOptions{MaxBytes: 1000}, "*.md")
+	if !errors.Is(err, ErrExtractLimit) || KFile.IsExist(dst+"/a.md") {
+		t.Error("ExtractFilesWith fail")
+		return
+	}
+
+	res, err := KFile.ExtractFilesWith(src, dst, ExtractOptions{MaxFiles: 2, MaxBytes: 10001}, "*.md")
+	if err != nil || len(res) != 2 || KFile.IsExist(dst+"/c.txt") {
+		t.Error("ExtractFilesWith fail")
+		return
+	}
+}
+
+func BenchmarkExtractFilesWith(b *testing.B) {
+	b.ResetTimer()
+	src := "./testdata/archive/bench.tar.gz"
+	_ = newTestTarGz(src, testArchiveEntry{name: "a.txt", body: "hello"}, testArchiveEntry{name: "b.md", body: "hello"})
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.ExtractFilesWith(src, "./testdata/archive/bench", ExtractOptions{MaxFiles: 10}, "*.md")
+	}
+}
+
+func BenchmarkExtractFiles(b *testing.B) {
+	b.ResetTimer()
+	src := "./testdata/archive/bench.tar.gz"
+	_ = newTestTarGz(src, testArchiveEntry{name: "a.txt", body: "hello"}, testArchiveEntry{name: "b.md", body: "hello"})
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.ExtractFiles(src, "./testdata/archive/bench", "*.md")
+	}
+}
+
+func TestReadArchiveFile(t *testing.T) {
+	zsrc := "./testdata/archive/read.zip"
+	tsrc := "./testdata/archive/read.tar.gz"
+	entries := []testArchiveEntry{{name: "docs/"}, {name: "docs/a.md", body: "hello"}, {name: "b.txt", body: "world"}}
+	_ = newTestZip(zsrc, entries...)
+	_ = newTestTarGz(tsrc, entries...)
+
+	for _, src := range []string{zsrc, tsrc} {
+		rc, err := KFile.ReadArchiveFile(src, "/docs/a.md")
+		if err != nil {
+			t.Error("ReadArchiveFile fail")
+			return
+		}
+		data, _ := ioutil.ReadAll(rc)
+		if string(data) != "hello" || rc.Close() != nil {
+			t.Error("ReadArchiveFile fail")
+			return
+		}
+
+		_, err = KFile.ReadArchiveFile(src, "none.txt")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Error("ReadArchiveFile fail")
+			return
+		}
+		_, err = KFile.ReadArchiveFile(src, "docs")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Error("ReadArchiveFile fail")
+			return
+		}
+	}
+
+	_, err := KFile.ReadArchiveFile("./testdata/dante.txt", "a.md")
+	if err == nil {
+		t.Error("ReadArchiveFile fail")
+		return
+	}
+}
+
+func BenchmarkReadArchiveFile(b *testing.B) {
+	b.ResetTimer()
+	src := "./testdata/archive/bench.zip"
+	_ = newTestZip(src, testArchiveEntry{name: "a.txt", body: "hello"})
+	for i := 0; i < b.N; i++ {
+		rc, err := KFile.ReadArchiveFile(src, "a.txt")
+		if err == nil {
+			_, _ = ioutil.ReadAll(rc)
+			_ = rc.Close()
+		}
+	}
+}
+
+func TestAppendZip(t *testing.T) {
+	zfile := "./testdata/archive/append.zip"
+	_ = KFile.Unlink(zfile)
+
+	//不存在时创建
+	res, err := KFile.AppendZip(zfile, "./testdata/dante.txt")
+	if !res || err != nil {
+		t.Error("AppendZip fail")
+		return
+	}
+
+	res, err = KFile.AppendZip(zfile, "./testdata/dante.txt", "./testdata/jetbrains.svg", "./testdata/rsa")
+	list, _ := KFile.ListArchive(zfile)
+	if !res || err != nil || len(list) < 3 || list[0].Name != "./testdata/dante.txt" || list[1].Name != "./testdata/jetbrains.svg" {
+		t.Error("AppendZip fail")
+		return
+	}
+
+	//标准库可正常读取所有条目
+	reader, err := zip.OpenReader(zfile)
+	if err != nil {
+		t.Error("AppendZip fail")
+		return
+	}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Error("AppendZip fail")
+			return
+		}
+		data, _ := ioutil.ReadAll(rc)
+		_ = rc.Close()
+		ori, _ := KFile.ReadFile(f.Name)
+		if !bytes.Equal(data, ori) {
+			t.Error("AppendZip fail")
+			return
+		}
+	}
+	_ = reader.Close()
+
+	//保留注释
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("a.txt")
+	_, _ = w.Write([]byte("hello"))
+	_ = zw.SetComment("kgo comment")
+	_ = zw.Close()
+	_ = KFile.WriteFile(zfile, buf.Bytes())
+	_, err = KFile.AppendZip(zfile, "./testdata/dante.txt")
+	reader, _ = zip.OpenReader(zfile)
+	if err != nil || reader == nil || len(reader.File) != 2 || reader.Comment != "kgo comment" {
+		t.Error("AppendZip fail")
+		return
+	}
+	_ = reader.Close()
+
+	//无效的zip保持不变
+	_ = KFile.WriteFile(zfile, []byte("PK\x03\x04 broken"))
+	_, err = KFile.AppendZip(zfile, "./testdata/dante.txt")
+	data, _ := KFile.ReadFile(zfile)
+	if err == nil || string(data) != "PK\x03\x04 broken" {
+		t.Error("AppendZip fail")
+		return
+	}
+
+	_, err = KFile.AppendZip(zfile)
+	if err == nil {
+		t.Error("AppendZip fail")
+		return
+	}
+	_, _ = KFile.Zip(zfile, "./testdata/dante.txt")
+	_, err = KFile.AppendZip(zfile, "./testdata/none.txt")
+	reader, _ = zip.OpenReader(zfile)
+	if err == nil || reader == nil || len(reader.File) != 1 {
+		t.Error("AppendZip fail")
+		return
+	}
+	_ = reader.Close()
+
+	//zip前有其他数据,如自解压文件
+	prefixed := append([]byte("#!/bin/sh\nexit 0\n"), newTestZipBytes(testArchiveEntry{name: "a.txt", body: "a"})...)
+	_ = ioutil.WriteFile(zfile, prefixed, 0644)
+	_, err = KFile.AppendZip(zfile, "./testdata/dante.txt")
+	data, _ = ioutil.ReadFile(zfile)
+	if err == nil || !bytes.Equal(data, prefixed) {
+		t.Error("AppendZip fail")
+		return
+	}
+}
+
+func BenchmarkAppendZip(b *testing.B) {
+	b.ResetTimer()
+	zfile := "./testdata/archive/bench_append.zip"
+	_, _ = KFile.Zip(zfile, "./testdata/dante.txt")
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.AppendZip(zfile, "./README.md")
+	}
+}
+
+// newTestPermTree 在dir下创建用于测试权限、修改时间和符号链接的文件.
+func newTestPermTree(dir string) time.Time {
+	mtime := time.Date(2020, 5, 9, 12, 0, 0, 0, time.UTC)
+	_ = os.RemoveAll(dir)
+	_ = os.MkdirAll(dir+"/bin", 0755)
+	_ = ioutil.WriteFile(dir+"/bin/run.sh", []byte("run\n"), 0755)
+	_ = ioutil.WriteFile(dir+"/secret.txt", []byte("secret\n"), 0600)
+	_ = os.Chmod(dir+"/bin/run.sh", 0755)
+	_ = os.Chmod(dir+"/secret.txt", 0600)
+	_ = os.Chtimes(dir+"/bin/run.sh", mtime, mtime)
+	_ = os.Chtimes(dir+"/secret.txt", mtime, mtime)
+	_ = os.Symlink("bin/run.sh", dir+"/run")
+	return mtime
+}
+
+// checkTestPermTree 检查解压后的dir是否保留了newTestPermTree的权限、修改时间和符号链接.
+func checkTestPermTree(dir string, mtime time.Time) bool {
+	run, err1 := os.Stat(dir + "/bin/run.sh")
+	secret, err2 := os.Stat(dir + "/secret.txt")
+	link, err3 := os.Readlink(dir + "/run")
+	body, err4 := ioutil.ReadFile(dir + "/run")
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return false
+	}
+
+	return run.Mode().Perm() == 0755 && secret.Mode().Perm() == 0600 && link == "bin/run.sh" && string(body) == "run\n" &&
+		run.ModTime().Equal(mtime) && secret.ModTime().Equal(mtime)
+}
+
+func TestTarUnTar(t *testing.T) {
+	src := "./testdata/archive/tar-src"
+	dst := "./testdata/archive/tar-dst"
+	mtime := newTestPermTree(src)
+	_ = os.RemoveAll(dst)
+
+	res, err := KFile.Tar(src, "./testdata/archive/perm.tar")
+	if !res || err != nil {
+		t.Error("Tar fail")
+		return
+	}
+
+	opt := ExtractOptions{Symlink: SYMLINK_INTERNAL}
+	res, err = KFile.UnTar("./testdata/archive/perm.tar", dst, opt)
+	if !res || err != nil || !checkTestPermTree(dst+"/tar-src", mtime) {
+		t.Error("UnTar fail")
+		return
+	}
+
+	//链接的目录不展开
+	_ = os.Symlink("bin", src+"/bin-lnk")
+	_, _ = KFile.Tar(src, "./testdata/archive/perm.tar")
+	list, err := KFile.ListArchive("./testdata/archive/perm.tar")
+	if err != nil || len(list) != 5 {
+		t.Error("Tar fail")
+		return
+	}
+
+	_, err = KFile.Tar("./testdata/archive/none", "./testdata/archive/none.tar")
+	if err == nil {
+		t.Error("Tar fail")
+		return
+	}
+	_, err = KFile.UnTar("./testdata/archive/none.tar", dst)
+	if err == nil {
+		t.Error("UnTar fail")
+		return
+	}
+}
+
+func BenchmarkTar(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.Tar("./README.md", "./testdata/archive/bench.tar")
+	}
+}
+
+func BenchmarkUnTar(b *testing.B) {
+	b.ResetTimer()
+	_, _ = KFile.Tar("./README.md", "./testdata/archive/bench.tar")
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.UnTar("./testdata/archive/bench.tar", "./testdata/archive/bench-tar")
+	}
+}
+
+func TestTarGzLevel(t *testing.T) {
+	src := "./testdata/archive/targz-src"
+	dst := "./testdata/archive/targz-dst"
+	mtime := newTestPermTree(src)
+	_ = os.RemoveAll(dst)
+
+	res, err := KFile.TarGzLevel(src, "./testdata/archive/perm.tar.gz", gzip.BestCompression)
+	if !res || err != nil {
+		t.Error("TarGzLevel fail")
+		return
+	}
+
+	opt := ExtractOptions{Symlink: SYMLINK_INTERNAL}
+	res, err = KFile.UnTarGz("./testdata/archive/perm.tar.gz", dst, opt)
+	if !res || err != nil || !checkTestPermTree(dst+"/targz-src", mtime) {
+		t.Error("TarGzLevel fail")
+		return
+	}
+
+	_, err = KFile.TarGzLevel(src, "./testdata/archive/perm.tar.gz", 10)
+	if err == nil {
+		t.Error("TarGzLevel fail")
+		return
+	}
+}
+
+func BenchmarkTarGzLevel(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.TarGzLevel("./README.md", "./testdata/archive/bench-level.tar.gz", gzip.BestSpeed)
+	}
+}
+
+func TestUnTarBz2(t *testing.T) {
+	dst := "./testdata/archive/tarbz2-dst"
+	_ = os.RemoveAll(dst)
+
+	opt := ExtractOptions{Symlink: SYMLINK_INTERNAL}
+	res, err := KFile.UnTarBz2("./testdata/perm.tar.bz2", dst, opt)
+	if !res || err != nil || !checkTestPermTree(dst+"/perm", time.Date(2020, 5, 9, 12, 0, 0, 0, time.UTC)) {
+		t.Error("UnTarBz2 fail")
+		return
+	}
+
+	list, err := KFile.ListArchive("./testdata/perm.tar.bz2")
+	if err != nil || len(list) != 5 {
+		t.Error("UnTarBz2 fail")
+		return
+	}
+	rc, err := KFile.ReadArchiveFile("./testdata/perm.tar.bz2", "perm/secret.txt")
+	if err != nil {
+		t.Error("UnTarBz2 fail")
+		return
+	}
+	body, _ := ioutil.ReadAll(rc)
+	_ = rc.Close()
+	if string(body) != "secret\n" {
+		t.Error("UnTarBz2 fail")
+		return
+	}
+
+	_, err = KFile.UnTarBz2("./testdata/dante.txt", dst)
+	if err == nil {
+		t.Error("UnTarBz2 fail")
+		return
+	}
+}
+
+func BenchmarkUnTarBz2(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.UnTarBz2("./testdata/perm.tar.bz2", "./testdata/archive/bench-bz2")
+	}
+}
+
+func TestZipLevel(t *testing.T) {
+	src := "./testdata/archive/zip-src"
+	dst := "./testdata/archive/zip-dst"
+	mtime := newTestPermTree(src)
+	_ = os.RemoveAll(dst)
+
+	res, err := KFile.ZipLevel("./testdata/archive/perm.zip", flate.BestCompression, src)
+	if !res || err != nil {
+		t.Error("ZipLevel fail")
+		return
+	}
+
+	opt := ExtractOptions{Symlink: SYMLINK_INTERNAL}
+	res, err = KFile.UnZip("./testdata/archive/perm.zip", dst, opt)
+	if !res || err != nil || !checkTestPermTree(dst+"/testdata/archive/zip-src", mtime) {
+		t.Error("ZipLevel fail")
+		return
+	}
+
+	_, err = KFile.ZipLevel("./testdata/archive/perm.zip", -3, src)
+	if err == nil {
+		t.Error("ZipLevel fail")
+		return
+	}
+}
+
+func BenchmarkZipLevel(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.ZipLevel("./testdata/archive/bench-level.zip", flate.BestSpeed, "./README.md")
+	}
+}
+
+func TestGzipGunzip(t *testing.T) {
+	src := "./testdata/archive/gzip-src.txt"
+	mtime := time.Date(2020, 5, 9, 12, 0, 0, 0, time.UTC)
+	_ = ioutil.WriteFile(src, []byte(strings.Repeat("hello ", 1000)), 0600)
+	_ = os.Chmod(src, 0600)
+	_ = os.Chtimes(src, mtime, mtime)
+
+	err := KFile.Gzip(src, "./testdata/archive/gzip-src.txt.gz", gzip.BestCompression)
+	if err != nil {
+		t.Error("Gzip fail")
+		return
+	}
+	fr, _ := os.Open("./testdata/archive/gzip-src.txt.gz")
+	gr, err := gzip.NewReader(fr)
+	if err != nil || gr.Name != "gzip-src.txt" || !gr.ModTime.Equal(mtime) {
+		_ = fr.Close()
+		t.Error("Gzip fail")
+		return
+	}
+	_ = fr.Close()
+
+	err = KFile.Gunzip("./testdata/archive/gzip-src.txt.gz", "./testdata/archive/gzip-dst.txt")
+	body, _ := ioutil.ReadFile("./testdata/archive/gzip-dst.txt")
+	stat, _ := os.Stat("./testdata/archive/gzip-dst.txt")
+	if err != nil || string(body) != strings.Repeat("hello ", 1000) || stat == nil || !stat.ModTime().Equal(mtime) || stat.Mode().Perm() != 0600 {
+		t.Error("Gunzip fail")
+		return
+	}
+
+	err = KFile.Gzip(src, "./testdata/archive/gzip-src.txt.gz", 10)
+	if err == nil {
+		t.Error("Gzip fail")
+		return
+	}
+	err = KFile.Gzip("./testdata/archive/none.txt", "./testdata/archive/none.txt.gz")
+	if err == nil {
+		t.Error("Gzip fail")
+		return
+	}
+	err = KFile.Gunzip("./testdata/dante.txt", "./testdata/archive/gzip-dst.txt")
+	if err == nil {
+		t.Error("Gunzip fail")
+		return
+	}
+}
+
+func BenchmarkGzip(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = KFile.Gzip("./README.md", "./testdata/archive/bench.md.gz")
+	}
+}
+
+func BenchmarkGunzip(b *testing.B) {
+	b.ResetTimer()
+	_ = KFile.Gzip("./README.md", "./testdata/archive/bench.md.gz")
+	for i := 0; i < b.N; i++ {
+		_ = KFile.Gunzip("./testdata/archive/bench.md.gz", "./testdata/archive/bench.md")
+	}
+}
+
+func BenchmarkZip(b *testing.B) {
+	b.ResetTimer()
+	src := "./README.md"
+	for i := 0; i < b.N; i++ {
+		dst := fmt.Sprintf("./zip/test_%d.zip", i)
+		_, _ = KFile.Zip(dst, src)
+	}
+}
+
+func BenchmarkUnzip(b *testing.B) {
+	b.ResetTimer()
+	var src, dst string
+	for i := 0; i < b.N; i++ {
+		src = fmt.Sprintf("./zip/test_%d.zip", i)
+		dst = fmt.Sprintf("./zip/unzip/test_%d", i)
+		_, _ = KFile.UnZip(src, dst)
+	}
+}
+
+func TestGetFileMode(t *testing.T) {
+	filepath := "./testdata/dante.txt"
+	perm, err := KFile.GetFileMode(filepath)
+	if err != nil || perm <= 0 {
+		t.Error("GetFileMode fail")
+		return
+	}
+
+	perm, err = KFile.GetFileMode("/root/hello/world")
+	if err == nil || perm != 0 {
+		t.Error("GetFileMode fail")
+		return
+	}
+}
+
+func BenchmarkGetFileMode(b *testing.B) {
+	b.ResetTimer()
+	filepath := "./testdata/dante.txt"
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.GetFileMode(filepath)
+	}
+}
+
+func TestGetFileOwner(t *testing.T) {
+	res, err := KFile.GetFileOwner("./testdata/dante.txt")
+	if err != nil || res.Uid != os.Geteuid() || res.Mode <= 0 {
+		t.Error("GetFileOwner fail")
+		return
+	}
+
+	res, err = KFile.GetFileOwner("/etc/passwd")
+	if err != nil || res.Uid != 0 || res.User != "root" {
+		t.Error("GetFileOwner fail")
+		return
+	}
+
+	_, err = KFile.GetFileOwner("/root/hello/world")
+	if err == nil {
+		t.Error("GetFileOwner fail")
+		return
+	}
+}
+
+func BenchmarkGetFileOwner(b *testing.B) {
+	b.ResetTimer()
+	filepath := "./testdata/dante.txt"
+	for i := 0; i < b.N; i++ {
+		_, _ = KFile.GetFileOwner(filepath)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	filename := "./testdata/access/hello.txt"
+	_ = KFile.WriteFile(filename, []byte("hello"), 0600)
+	_ = os.Chmod(filename, 0600)
+
+	if err := KFile.CheckAccess(filename, "rw"); err != nil {
+		t.Error("CheckAccess fail")
+		return
+	}
+
+	var ae *AccessError
+	err := KFile.CheckAccess(filename, "x")
+	if !errors.As(err, &ae) || ae.Perm != "x" || !errors.Is(err, ErrAccessMode) {
+		t.Error("CheckAccess fail")
+		return
+	}
+
+	err = KFile.CheckAccess("./testdata/access/nothing", "r")
+	if !os.IsNotExist(err) {
+		t.Error("CheckAccess fail")
+		return
+	}
+
+	if KOS.IsRoot() {
+		return
+	}
+
+	//其他用户的私有目录
+	err = KFile.CheckAccess("/root/hello", "r")
+	if !errors.As(err, &ae) || ae.Path != "/root" || !errors.Is(err, ErrAccessOwner) {
+		t.Error("CheckAccess fail")
+		return
+	}
+
+	err = KFile.CheckAccess("/etc/passwd", "w")
+	if !errors.Is(err, ErrAccessOwner) {
+		t.Error("CheckAccess fail")
+		return
+	}
+}
+
+func BenchmarkCheckAccess(b *testing.B) {
+	b.ResetTimer()
+	filename := "./README.md"
+	for i := 0; i < b.N; i++ {
+		_ = KFile.CheckAccess(filename, "r")
+	}
+}
+
+func TestAppendFile(t *testing.T) {
+	cont := []byte("hello world.")
+	err := KFile.AppendFile("", cont)
+	if err == nil {
+		t.Error("AppendFile fail")
+		return
+	}
+
+	pth := "./testdata/append.txt"
+	err = KFile.AppendFile(pth, cont)
+	if err != nil {
+		t.Error("AppendFile fail")
+		return
+	}
+
+	err = KFile.AppendFile(pth, []byte("how are you?"))
+	if err != nil {
+		t.Error("AppendFile fail")
+		return
+	}
+
+	_ = KFile.AppendFile("/root/hello/world", []byte("how are you?"))
+	_ = KFile.AppendFile(pth, []byte(""))
+}
+
+func BenchmarkAppendFile(b *testing.B) {
+	b.ResetTimer()
+	pth := "./testdata/append.txt"
+	cont := []byte("hello world.\r\n")
+	for i := 0; i < b.N; i++ {
+		_ = KFile.AppendFile(pth, cont)
+	}
+}
+
+func TestReadFirstLine(t *testing.T) {
+	var tests = []struct {
+		file     string
+		expected string
+	}{
+		{"", ""},
+		{"./testdata/firstline.log", ""},
+		{"./testdata/dante.txt", "LA DIVINA COMMEDIA"},
+		{"docs/changelog.md", "# Changelog"},
+	}
+	for _, test := range tests {
+		actual := KFile.ReadFirstLine(test.file)
+		if actual != test.expected {
+			t.Errorf("Expected FirstLine(%q) to be %v, got %v", test.file, test.expected, actual)
+		}
+	}
+}
+
+func BenchmarkReadFirstLine(b *testing.B) {
+	b.ResetTimer()
+	fpath := "./testdata/dante.txt"
+	for i := 0; i < b.N; i++ {
+		KFile.ReadFirstLine(fpath)
+	}
+}
+
+func TestReadLastLine(t *testing.T) {
+	tfile1 := "./testdata/lastline1.log"
+	tfile2 := "./testdata/lastline2.log"
+	str := "hello World"
+	KFile.Touch(tfile1, 0)
+	_ = KFile.WriteFile(tfile2, []byte(str))
+
+	var tests = []struct {
+		file     string
+		expected string
+	}{
+		{"", ""},
+		{"./testdata/firstline.log", ""},
+		{tfile1, ""},
+		{tfile2, str},
+		{"./testdata/dante.txt", ""},
+		{"docs/changelog.md", "*--end of file--*"},
+	}
+	for _, test := range tests {
+		actual := KFile.ReadLastLine(test.file)
+		if KStr.Trim(actual) != test.expected {
+			t.Errorf("Expected FirstLine(%q) to be [%s], got [%s]", test.file, test.expected, actual)
+		}
+	}
+}
+
+func BenchmarkReadLastLine(b *testing.B) {
+	b.ResetTimer()
+	fpath := "./testdata/dante.txt"
+	for i := 0; i < b.N; i++ {
+		KFile.ReadLastLine(fpath)
+	}
+}
+
+func TestEncryptFileDecryptFile(t *testing.T) {
+	src := "./testdata/dante.txt"
+	enc := "./testdata/crypt/dante.txt.enc"
+	dec := "./testdata/crypt/dante.txt"
+	key := []byte("backup-key")
+
+	err := KFile.EncryptFile(src, enc, key)
+	if err != nil {
+		t.Error("EncryptFile fail")
+		return
+	}
+
+	err = KFile.DecryptFile(enc, dec, key)
+	ori, _ := KFile.ReadFile(src)
+	des, _ := KFile.ReadFile(dec)
+	if err != nil || string(ori) != string(des) {
+		t.Error("DecryptFile fail")
+		return
+	}
+
+	//错误的密钥,不生成目标文件
+	_ = KFile.Unlink(dec)
+	err = KFile.DecryptFile(enc, dec, []byte("wrong"))
+	if !errors.Is(err, ErrAuthFailed) || KFile.IsExist(dec) {
+		t.Error("DecryptFile fail")
+		return
+	}
+
+	//截断
+	data, _ := KFile.ReadFile(enc)
+	_ = KFile.WriteFile(enc, data[:len(data)-20])
+	err = KFile.DecryptFile(enc, dec, key)
+	if err == nil || KFile.IsExist(dec) {
+		t.Error("DecryptFile fail")
+		return
+	}
+
+	err = KFile.EncryptFile("./testdata/hello-none", enc, key)
+	if err == nil {
+		t.Error("EncryptFile fail")
+		return
+	}
+	err = KFile.EncryptFile("./testdata", enc, key)
+	if err == nil {
+		t.Error("EncryptFile fail")
+		return
+	}
+	err = KFile.DecryptFile(src, dec, key)
+	if err != ErrStreamHeader {
+		t.Error("DecryptFile fail")
+		return
+	}
+}
+
+func BenchmarkEncryptFile(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("backup-key")
+	for i := 0; i < b.N; i++ {
+		_ = KFile.EncryptFile("./testdata/dante.txt", "./testdata/crypt/bench.enc", key)
+	}
+}
+
+func BenchmarkDecryptFile(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("backup-key")
+	_ = KFile.EncryptFile("./testdata/dante.txt", "./testdata/crypt/bench.enc", key)
+	for i := 0; i < b.N; i++ {
+		_ = KFile.DecryptFile("./testdata/crypt/bench.enc", "./testdata/crypt/bench.txt", key)
 	}
 }
@@ -2,12 +2,17 @@ package kgo
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/md5"
+	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/x509"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"hash"
 	"os"
@@ -64,6 +69,84 @@ func shaXStr(str []byte, x uint16) []byte {
 	return res
 }
 
+// shaXHash 获取shaX对应的散列算法,x为256/384/512.
+func shaXHash(x uint16) (crypto.Hash, error) {
+	switch x {
+	case 256:
+		return crypto.SHA256, nil
+	case 384:
+		return crypto.SHA384, nil
+	case 512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported hash sha%d, x must be in [256, 384, 512]", x)
+	}
+}
+
+// parsePemPrivateKey 解析pem格式的私钥,支持PKCS1/PKCS8/EC格式.
+func parsePemPrivateKey(key []byte) (interface{}, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("private key error")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+}
+
+// parsePemPublicKey 解析pem格式的公钥,支持PKIX/PKCS1格式.
+func parsePemPublicKey(key []byte) (interface{}, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("public key error")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if rsaPub, e := x509.ParsePKCS1PublicKey(block.Bytes); e == nil {
+			return rsaPub, nil
+		}
+	}
+
+	return pub, err
+}
+
+// parseRsaPrivateKey 解析pem格式的RSA私钥.
+func parseRsaPrivateKey(key []byte) (*rsa.PrivateKey, error) {
+	priv, err := parsePemPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaPriv, ok := priv.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not a rsa private key")
+	}
+
+	return rsaPriv, nil
+}
+
+// parseRsaPublicKey 解析pem格式的RSA公钥.
+func parseRsaPublicKey(key []byte) (*rsa.PublicKey, error) {
+	pub, err := parsePemPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not a rsa public key")
+	}
+
+	return rsaPub, nil
+}
+
 // isArrayOrSlice 检查变量是否数组或切片.
 // chkType为检查类型,枚举值有(1仅数组,2仅切片,3数组或切片);结果为-1表示非,>=0表示是.
 func isArrayOrSlice(data interface{}, chkType uint8) int {
@@ -531,3 +614,77 @@ func zeroUnPadding(origData []byte) []byte {
 		return r == rune(0)
 	})
 }
+
+// baseXEncode 将数据按大整数转换为以alphabet为字母表的字符串,前导的0字节编码为alphabet[0].
+func baseXEncode(data []byte, alphabet string) string {
+	base := len(alphabet)
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	// 小端存储的各位数字
+	digits := make([]int, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for j := range digits {
+			carry += digits[j] << 8
+			digits[j] = carry % base
+			carry /= base
+		}
+		for carry > 0 {
+			digits = append(digits, carry%base)
+			carry /= base
+		}
+	}
+
+	res := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		res[i] = alphabet[0]
+	}
+	for i, d := range digits {
+		res[len(res)-1-i] = alphabet[d]
+	}
+	return string(res)
+}
+
+// baseXDecode 解码baseXEncode生成的字符串;name用于错误信息.
+func baseXDecode(str, alphabet, name string) ([]byte, error) {
+	var index [256]int
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		index[alphabet[i]] = i
+	}
+
+	base := len(alphabet)
+	zeros := 0
+	for zeros < len(str) && str[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	// 小端存储的字节
+	buf := make([]byte, 0, len(str))
+	for i := zeros; i < len(str); i++ {
+		carry := index[str[i]]
+		if carry < 0 {
+			return nil, fmt.Errorf("[%s] invalid character %q at offset %d", name, str[i], i)
+		}
+		for j := range buf {
+			carry += int(buf[j]) * base
+			buf[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			buf = append(buf, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	res := make([]byte, zeros+len(buf))
+	for i, b := range buf {
+		res[len(res)-1-i] = b
+	}
+	return res, nil
+}
@@ -0,0 +1,305 @@
+package kgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultHttpRetryStatus 默认需要重试的状态码
+var defaultHttpRetryStatus = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// defaultHttpClient 供KHttp.Get等方法使用的默认客户端
+var (
+	defaultHttpClient     *HttpClient
+	defaultHttpClientOnce sync.Once
+)
+
+// NewClient 创建http客户端,默认超时30秒,不重试.
+func (kh *LkkHttp) NewClient() *HttpClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	return &HttpClient{
+		client:      &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		transport:   transport,
+		headers:     make(http.Header),
+		retryWait:   100 * time.Millisecond,
+		retryStatus: defaultHttpRetryStatus,
+	}
+}
+
+// getDefaultHttpClient 获取默认客户端.
+func getDefaultHttpClient() *HttpClient {
+	defaultHttpClientOnce.Do(func() {
+		defaultHttpClient = KHttp.NewClient()
+	})
+	return defaultHttpClient
+}
+
+// Get 使用默认客户端发送GET请求,query为可选的查询参数.
+func (kh *LkkHttp) Get(rawurl string, query ...url.Values) (*HttpResponse, error) {
+	return getDefaultHttpClient().Get(rawurl, query...)
+}
+
+// Post 使用默认客户端发送POST请求.
+func (kh *LkkHttp) Post(rawurl string, body []byte, contentType string) (*HttpResponse, error) {
+	return getDefaultHttpClient().Post(rawurl, body, contentType)
+}
+
+// PostForm 使用默认客户端发送表单POST请求.
+func (kh *LkkHttp) PostForm(rawurl string, data url.Values) (*HttpResponse, error) {
+	return getDefaultHttpClient().PostForm(rawurl, data)
+}
+
+// PostJSON 使用默认客户端发送JSON格式的POST请求.
+func (kh *LkkHttp) PostJSON(rawurl string, data interface{}) (*HttpResponse, error) {
+	return getDefaultHttpClient().PostJSON(rawurl, data)
+}
+
+// SetTimeout 设置请求超时时间(含读取响应体),0为不限.
+func (hc *HttpClient) SetTimeout(timeout time.Duration) *HttpClient {
+	hc.client.Timeout = timeout
+	return hc
+}
+
+// SetHeader 设置每个请求的默认头.
+func (hc *HttpClient) SetHeader(key, value string) *HttpClient {
+	hc.headers.Set(key, value)
+	return hc
+}
+
+// SetHeaders 批量设置每个请求的默认头.
+func (hc *HttpClient) SetHeaders(headers map[string]string) *HttpClient {
+	for k, v := range headers {
+		hc.headers.Set(k, v)
+	}
+	return hc
+}
+
+// SetCookies 设置每个请求都携带的cookie.
+func (hc *HttpClient) SetCookies(cookies ...*http.Cookie) *HttpClient {
+	hc.cookies = append(hc.cookies, cookies...)
+	return hc
+}
+
+// SetProxy 设置代理,如http://127.0.0.1:8080或socks5://127.0.0.1:1080;为空时不使用代理.
+func (hc *HttpClient) SetProxy(proxy string) (*HttpClient, error) {
+	if proxy == "" {
+		hc.transport.Proxy = nil
+		return hc, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return hc, err
+	} else if u.Scheme == "" || u.Host == "" {
+		return hc, fmt.Errorf("[SetProxy] invalid proxy: %s", proxy)
+	}
+	hc.transport.Proxy = http.ProxyURL(u)
+	return hc, nil
+}
+
+// SetRetry 设置重试.times为最大重试次数,wait为首次重试前的等待时间,之后每次翻倍;
+// statusCodes为需要重试的状态码,默认429、502、503、504.网络错误总是重试.
+func (hc *HttpClient) SetRetry(times int, wait time.Duration, statusCodes ...int) *HttpClient {
+	hc.retries = times
+	hc.retryWait = wait
+	if len(statusCodes) > 0 {
+		hc.retryStatus = statusCodes
+	}
+	return hc
+}
+
+// shouldRetry 该状态码是否需要重试.
+func (hc *HttpClient) shouldRetry(code int) bool {
+	for _, c := range hc.retryStatus {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Do 发送请求.newBody在每次尝试时调用,以便重试时重新生成请求体,返回请求体和Content-Type;为nil时无请求体.
+func (hc *HttpClient) Do(method, rawurl string, newBody func() (io.Reader, string, error)) (res *HttpResponse, err error) {
+	wait := hc.retryWait
+	for attempt := 0; ; attempt++ {
+		res, err = hc.doOnce(method, rawurl, newBody)
+		if attempt >= hc.retries || (err == nil && !hc.shouldRetry(res.StatusCode)) {
+			return
+		} else if _, ok := err.(*url.Error); err != nil && !ok {
+			// 非网络错误(如请求体生成失败)不重试
+			return
+		}
+
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// doOnce 发送一次请求并读取完整的响应.
+func (hc *HttpClient) doOnce(method, rawurl string, newBody func() (io.Reader, string, error)) (*HttpResponse, error) {
+	var body io.Reader
+	var contentType string
+	if newBody != nil {
+		var err error
+		if body, contentType, err = newBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, rawurl, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range hc.headers {
+		req.Header[k] = v
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, c := range hc.cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := hc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HttpResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		body:       data,
+	}, nil
+}
+
+// bytesBody 生成固定内容的请求体.
+func bytesBody(data []byte, contentType string) func() (io.Reader, string, error) {
+	return func() (io.Reader, string, error) {
+		return bytes.NewReader(data), contentType, nil
+	}
+}
+
+// Get 发送GET请求,query为可选的查询参数,会追加到URL中.
+func (hc *HttpClient) Get(rawurl string, query ...url.Values) (*HttpResponse, error) {
+	if len(query) > 0 && len(query[0]) > 0 {
+		if strings.Contains(rawurl, "?") {
+			rawurl += "&" + KStr.HttpBuildQuery(query[0])
+		} else {
+			rawurl += "?" + KStr.HttpBuildQuery(query[0])
+		}
+	}
+	return hc.Do(http.MethodGet, rawurl, nil)
+}
+
+// Post 发送POST请求.
+func (hc *HttpClient) Post(rawurl string, body []byte, contentType string) (*HttpResponse, error) {
+	return hc.Do(http.MethodPost, rawurl, bytesBody(body, contentType))
+}
+
+// PostForm 发送表单POST请求.
+func (hc *HttpClient) PostForm(rawurl string, data url.Values) (*HttpResponse, error) {
+	return hc.Post(rawurl, []byte(KStr.HttpBuildQuery(data)), "application/x-www-form-urlencoded")
+}
+
+// PostJSON 发送JSON格式的POST请求.
+func (hc *HttpClient) PostJSON(rawurl string, data interface{}) (*HttpResponse, error) {
+	body, err := KStr.JsonEncode(data)
+	if err != nil {
+		return nil, err
+	}
+	return hc.Post(rawurl, body, "application/json")
+}
+
+// Put 发送PUT请求.
+func (hc *HttpClient) Put(rawurl string, body []byte, contentType string) (*HttpResponse, error) {
+	return hc.Do(http.MethodPut, rawurl, bytesBody(body, contentType))
+}
+
+// Delete 发送DELETE请求.
+func (hc *HttpClient) Delete(rawurl string) (*HttpResponse, error) {
+	return hc.Do(http.MethodDelete, rawurl, nil)
+}
+
+// Upload 以multipart/form-data上传文件.field为文件字段名,fpath为文件路径,fields为其他表单字段.
+// 文件以流的方式读取,不会全部载入内存.
+func (hc *HttpClient) Upload(rawurl, field, fpath string, fields map[string]string) (*HttpResponse, error) {
+	if _, err := os.Stat(fpath); err != nil {
+		return nil, err
+	}
+
+	return hc.Do(http.MethodPost, rawurl, func() (io.Reader, string, error) {
+		file, err := os.Open(fpath)
+		if err != nil {
+			return nil, "", err
+		}
+
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+		go func() {
+			defer func() {
+				_ = file.Close()
+			}()
+
+			for k, v := range fields {
+				if err := mw.WriteField(k, v); err != nil {
+					_ = pw.CloseWithError(err)
+					return
+				}
+			}
+			part, err := mw.CreateFormFile(field, filepath.Base(fpath))
+			if err == nil {
+				if _, err = io.Copy(part, file); err == nil {
+					err = mw.Close()
+				}
+			}
+			_ = pw.CloseWithError(err)
+		}()
+
+		return pr, mw.FormDataContentType(), nil
+	})
+}
+
+// IsOk 状态码是否2xx.
+func (hr *HttpResponse) IsOk() bool {
+	return hr.StatusCode >= 200 && hr.StatusCode < 300
+}
+
+// Bytes 获取响应体.
+func (hr *HttpResponse) Bytes() []byte {
+	return hr.body
+}
+
+// String 获取字符串形式的响应体.
+func (hr *HttpResponse) String() string {
+	return string(hr.body)
+}
+
+// JSON 将响应体解码到val,注意val使用指针.
+func (hr *HttpResponse) JSON(val interface{}) error {
+	return KStr.JsonDecode(hr.body, val)
+}
+
+// SaveTo 将响应体保存到文件,目录不存在时会创建.
+func (hr *HttpResponse) SaveTo(fpath string) error {
+	return KFile.WriteFile(fpath, hr.body, 0644)
+}
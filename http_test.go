@@ -0,0 +1,250 @@
+package kgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHttpServer 创建回显请求信息的测试服务.
+func newTestHttpServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		cookie, _ := r.Cookie("token")
+		var token string
+		if cookie != nil {
+			token = cookie.Value
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc"})
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := KStr.JsonEncode(map[string]string{
+			"method":       r.Method,
+			"query":        r.URL.RawQuery,
+			"body":         string(body),
+			"content_type": r.Header.Get("Content-Type"),
+			"agent":        r.Header.Get("User-Agent"),
+			"token":        token,
+		})
+		_, _ = w.Write(data)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		data, _ := ioutil.ReadAll(file)
+		_, _ = fmt.Fprintf(w, "%s:%s:%d", r.FormValue("name"), header.Filename, len(data))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestHttpClientRequest(t *testing.T) {
+	ts := newTestHttpServer()
+	defer ts.Close()
+
+	hc := KHttp.NewClient().SetHeader("User-Agent", "kgo").SetCookies(&http.Cookie{Name: "token", Value: "123"})
+	var res map[string]string
+
+	resp, err := hc.Get(ts.URL+"/echo?a=1", url.Values{"b": {"2"}})
+	if err != nil || !resp.IsOk() || resp.JSON(&res) != nil {
+		t.Error("HttpClient Get fail")
+		return
+	} else if res["method"] != "GET" || res["query"] != "a=1&b=2" || res["agent"] != "kgo" || res["token"] != "123" {
+		t.Error("HttpClient Get fail")
+		return
+	} else if len(resp.Cookies) != 1 || resp.Cookies[0].Value != "abc" {
+		t.Error("HttpClient Get fail")
+		return
+	}
+
+	resp, _ = hc.PostForm(ts.URL+"/echo", url.Values{"name": {"kgo"}})
+	_ = resp.JSON(&res)
+	if res["method"] != "POST" || res["body"] != "name=kgo" || res["content_type"] != "application/x-www-form-urlencoded" {
+		t.Error("HttpClient PostForm fail")
+		return
+	}
+
+	resp, _ = hc.PostJSON(ts.URL+"/echo", map[string]int{"id": 1})
+	_ = resp.JSON(&res)
+	if res["body"] != `{"id":1}` || res["content_type"] != "application/json" {
+		t.Error("HttpClient PostJSON fail")
+		return
+	}
+
+	resp, _ = hc.Put(ts.URL+"/echo", []byte("hello"), "text/plain")
+	_ = resp.JSON(&res)
+	if res["method"] != "PUT" || res["body"] != "hello" {
+		t.Error("HttpClient Put fail")
+		return
+	}
+
+	resp, _ = hc.Delete(ts.URL + "/echo")
+	_ = resp.JSON(&res)
+	if res["method"] != "DELETE" {
+		t.Error("HttpClient Delete fail")
+		return
+	}
+
+	_, err = hc.Get("http://127.0.0.1:1/nothing")
+	if err == nil {
+		t.Error("HttpClient Get fail")
+		return
+	}
+}
+
+func BenchmarkHttpClientGet(b *testing.B) {
+	ts := newTestHttpServer()
+	defer ts.Close()
+	hc := KHttp.NewClient()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = hc.Get(ts.URL + "/echo")
+	}
+}
+
+func TestKHttp(t *testing.T) {
+	ts := newTestHttpServer()
+	defer ts.Close()
+
+	var res map[string]string
+	resp, err := KHttp.Get(ts.URL+"/echo", url.Values{"a": {"1"}})
+	if err != nil || resp.JSON(&res) != nil || res["query"] != "a=1" {
+		t.Error("KHttp Get fail")
+		return
+	}
+
+	resp, _ = KHttp.Post(ts.URL+"/echo", []byte("raw"), "text/plain")
+	_ = resp.JSON(&res)
+	if res["body"] != "raw" {
+		t.Error("KHttp Post fail")
+		return
+	}
+
+	resp, _ = KHttp.PostForm(ts.URL+"/echo", url.Values{"a": {"1"}})
+	_ = resp.JSON(&res)
+	if res["body"] != "a=1" {
+		t.Error("KHttp PostForm fail")
+		return
+	}
+
+	resp, _ = KHttp.PostJSON(ts.URL+"/echo", []int{1, 2})
+	_ = resp.JSON(&res)
+	if res["body"] != "[1,2]" {
+		t.Error("KHttp PostJSON fail")
+		return
+	}
+}
+
+func TestHttpClientUpload(t *testing.T) {
+	ts := newTestHttpServer()
+	defer ts.Close()
+
+	fpath := "./testdata/dante.txt"
+	info, _ := os.Stat(fpath)
+	resp, err := KHttp.NewClient().Upload(ts.URL+"/upload", "file", fpath, map[string]string{"name": "kgo"})
+	if err != nil || resp.String() != fmt.Sprintf("kgo:dante.txt:%d", info.Size()) {
+		t.Error("HttpClient Upload fail")
+		return
+	}
+
+	_, err = KHttp.NewClient().Upload(ts.URL+"/upload", "file", "./testdata/nothing", nil)
+	if err == nil {
+		t.Error("HttpClient Upload fail")
+		return
+	}
+}
+
+func TestHttpClientRetry(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	//重试时重新发送请求体
+	resp, err := KHttp.NewClient().SetRetry(3, time.Millisecond).Post(ts.URL, []byte("again"), "text/plain")
+	if err != nil || resp.StatusCode != http.StatusOK || resp.String() != "again" || atomic.LoadInt32(&hits) != 3 {
+		t.Error("HttpClient retry fail")
+		return
+	}
+
+	//重试次数用尽,返回最后的响应
+	atomic.StoreInt32(&hits, 0)
+	resp, err = KHttp.NewClient().SetRetry(1, time.Millisecond).Get(ts.URL)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&hits) != 2 {
+		t.Error("HttpClient retry fail")
+		return
+	}
+
+	//不在重试状态码内
+	atomic.StoreInt32(&hits, 0)
+	resp, _ = KHttp.NewClient().SetRetry(3, time.Millisecond, http.StatusBadGateway).Get(ts.URL)
+	if resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&hits) != 1 {
+		t.Error("HttpClient retry fail")
+		return
+	}
+}
+
+func TestHttpClientProxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		_, _ = w.Write([]byte("proxy:" + r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	hc, err := KHttp.NewClient().SetProxy(proxy.URL)
+	if err != nil {
+		t.Error("HttpClient SetProxy fail")
+		return
+	}
+	resp, err := hc.SetTimeout(5 * time.Second).Get("http://kgo.example/hello")
+	if err != nil || resp.String() != "proxy:http://kgo.example/hello" || atomic.LoadInt32(&proxied) != 1 {
+		t.Error("HttpClient SetProxy fail")
+		return
+	}
+
+	if _, err = hc.SetProxy("127.0.0.1"); err == nil {
+		t.Error("HttpClient SetProxy fail")
+		return
+	}
+	if _, err = hc.SetProxy(""); err != nil {
+		t.Error("HttpClient SetProxy fail")
+		return
+	}
+}
+
+func TestHttpResponseSaveTo(t *testing.T) {
+	ts := newTestHttpServer()
+	defer ts.Close()
+
+	resp, _ := KHttp.NewClient().SetHeaders(map[string]string{"User-Agent": "save"}).Get(ts.URL + "/echo")
+	fpath := "./testdata/http/echo.json"
+	if err := resp.SaveTo(fpath); err != nil {
+		t.Error("HttpResponse SaveTo fail")
+		return
+	}
+
+	data, _ := ioutil.ReadFile(fpath)
+	if string(data) != string(resp.Bytes()) || !strings.Contains(string(data), `"agent":"save"`) {
+		t.Error("HttpResponse SaveTo fail")
+		return
+	}
+}
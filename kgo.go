@@ -1,8 +1,20 @@
 package kgo
 
 import (
+	"bufio"
+	"container/list"
+	"context"
+	"crypto/cipher"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
 	"net"
+	"net/http"
+	"os"
 	"regexp"
+	"sync"
 	"time"
 )
 
@@ -25,6 +37,8 @@ type (
 	LkkEncrypt byte
 	// LkkDebug is the receiver of debug utilities
 	LkkDebug byte
+	// LkkHttp is the receiver of http utilities
+	LkkHttp byte
 
 	// LkkFileCover 枚举类型,文件是否覆盖
 	LkkFileCover int8
@@ -40,12 +54,502 @@ type (
 	LkkPadType uint8
 	// LkkPKCSType 枚举类型,PKCS填充类型
 	LkkPKCSType int8
+	// LkkAeadType 枚举类型,认证加密算法
+	LkkAeadType uint8
+	// LkkRsaPadding 枚举类型,RSA签名填充方式
+	LkkRsaPadding uint8
+	// LkkPasswordAlgo 枚举类型,密码散列算法
+	LkkPasswordAlgo uint8
+	// LkkSymlinkPolicy 枚举类型,解压时符号链接的处理方式
+	LkkSymlinkPolicy uint8
+
+	// LkkExistingPolicy 枚举类型,解压时目标文件已存在的处理方式
+	LkkExistingPolicy uint8
+	// LkkFileCategory 枚举类型,根据文件内容识别的文件类别
+	LkkFileCategory string
+
+	// PasswordPolicy 密码散列策略,值为0的参数使用默认值
+	PasswordPolicy struct {
+		Algo       LkkPasswordAlgo // 散列算法,默认PASSWORD_BCRYPT
+		Cost       int             // bcrypt的cost,范围4~31
+		Memory     uint32          // argon2id的内存,单位KiB
+		Time       uint32          // argon2id的迭代次数
+		Threads    uint8           // argon2id的并行度
+		N          int             // scrypt的CPU/内存开销,须为2的幂
+		R          int             // scrypt的块大小
+		P          int             // scrypt的并行度
+		Iterations int             // pbkdf2的迭代次数
+		SaltLen    int             // 盐的字节数
+		KeyLen     int             // 散列结果的字节数
+	}
 
 	// FileFilter 文件过滤函数
 	FileFilter func(string) bool
 
 	// CallBack 回调执行函数,无参数且无返回值
 	CallBack func()
+
+	// ProcessInfo 进程信息
+	ProcessInfo struct {
+		Pid       int       // 进程ID
+		Ppid      int       // 父进程ID
+		Name      string    // 进程名
+		Exe       string    // 执行文件路径
+		Cmdline   []string  // 命令行参数
+		Cwd       string    // 工作目录
+		State     string    // 状态码,如R/S/D/Z/T
+		Threads   int       // 线程数
+		StartTime time.Time // 启动时间
+		Rss       uint64    // 常驻内存,字节
+		Vsz       uint64    // 虚拟内存,字节
+		FdNum     int       // 打开的文件描述符数量
+	}
+
+	// ProcessError 进程操作错误
+	ProcessError struct {
+		Pid int    // 进程ID
+		Op  string // 操作名称
+		Err error  // 错误原因
+	}
+
+	// SocketInfo 网络套接字信息
+	SocketInfo struct {
+		Protocol   string // 协议,tcp/tcp6/udp/udp6
+		LocalIP    string // 本地IP
+		LocalPort  int    // 本地端口
+		RemoteIP   string // 远端IP
+		RemotePort int    // 远端端口
+		State      string // 状态,如LISTEN/ESTABLISHED
+		Uid        int    // 所属用户ID
+		Inode      uint64 // 套接字inode
+		Pid        int    // 所属进程ID,无权限查看时为0
+		Exe        string // 所属进程的执行路径
+	}
+
+	// PidFile 进程pid文件,创建后持有文件锁直至Remove
+	PidFile struct {
+		Path string   // 文件路径
+		Pid  int      // 进程ID
+		file *os.File // 已加锁的文件句柄
+	}
+
+	// SysCpuUsage CPU使用率,百分比
+	SysCpuUsage struct {
+		Percent float64   // 总使用率
+		Cores   []float64 // 各核心的使用率
+	}
+
+	// SysMemInfo 系统内存信息,单位字节
+	SysMemInfo struct {
+		Total           uint64  // 总内存
+		Free            uint64  // 空闲内存
+		Available       uint64  // 可用内存(含可回收的缓存)
+		Used            uint64  // 已用内存(Total-Available)
+		UsedPercent     float64 // 内存使用率
+		Buffers         uint64  // 缓冲区
+		Cached          uint64  // 页缓存
+		SwapTotal       uint64  // 交换区总量
+		SwapFree        uint64  // 交换区空闲
+		SwapUsed        uint64  // 交换区已用
+		SwapUsedPercent float64 // 交换区使用率
+	}
+
+	// SysLoadAvg 系统平均负载
+	SysLoadAvg struct {
+		Load1   float64 // 1分钟平均负载
+		Load5   float64 // 5分钟平均负载
+		Load15  float64 // 15分钟平均负载
+		Running int     // 正在运行的进程(线程)数
+		Total   int     // 进程(线程)总数
+	}
+
+	// SysDiskInfo 磁盘分区使用信息,单位字节
+	SysDiskInfo struct {
+		Device      string  // 设备
+		Mountpoint  string  // 挂载点
+		Fstype      string  // 文件系统类型
+		Total       uint64  // 总容量
+		Free        uint64  // 空闲容量(含root保留)
+		Avail       uint64  // 普通用户可用容量
+		Used        uint64  // 已用容量
+		UsedPercent float64 // 使用率,同df
+		Inodes      uint64  // inode总数
+		InodesFree  uint64  // inode空闲数
+	}
+
+	// NetInterfaceInfo 网络接口信息
+	NetInterfaceInfo struct {
+		Index int      // 接口序号
+		Name  string   // 接口名称
+		Mac   string   // MAC地址
+		Mtu   int      // 最大传输单元
+		Flags []string // 标志,如up/broadcast/loopback/multicast
+		Addrs []string // 地址,CIDR格式
+	}
+
+	// GracefulShutdown 优雅退出,监听SIGINT/SIGTERM/SIGHUP信号并按序执行钩子
+	GracefulShutdown struct {
+		ctx      context.Context
+		cancel   context.CancelFunc
+		mu       sync.Mutex
+		hooks    []shutdownHook
+		reloads  []CallBack
+		sigCh    chan os.Signal
+		done     chan struct{}
+		stopping bool
+		err      error
+		exit     func(code int)
+	}
+
+	// shutdownHook 退出钩子
+	shutdownHook struct {
+		name    string
+		fn      CallBack
+		timeout time.Duration
+	}
+
+	// FileOwnerInfo 文件所有者信息
+	FileOwnerInfo struct {
+		Uid   int         // 所有者ID
+		Gid   int         // 所属组ID
+		User  string      // 所有者名称,无法解析时为空
+		Group string      // 所属组名称,无法解析时为空
+		Mode  os.FileMode // 文件模式,同GetFileMode
+	}
+
+	// AccessError 文件访问检查的错误,说明拒绝访问的原因
+	AccessError struct {
+		Path string // 被拒绝访问的路径,可能是上级目录
+		Perm string // 所需权限,r/w/x
+		Err  error  // 原因
+	}
+
+	// ExtractOptions 解压选项,限制值为0时表示不限制
+	ExtractOptions struct {
+		Symlink  LkkSymlinkPolicy  // 符号链接的处理方式,默认SYMLINK_SKIP
+		MaxBytes int64             // 解压后文件的总字节数上限
+		MaxFiles int               // 文件(含链接)数量上限
+		MaxRatio float64           // 解压后总字节数与压缩包大小之比的上限
+		Existing LkkExistingPolicy // 目标文件已存在时的处理方式,默认EXISTING_OVERWRITE
+	}
+
+	// ExtractError 解压错误,说明出错的压缩包条目
+	ExtractError struct {
+		Entry string // 压缩包内的条目名称
+		Err   error  // 原因
+	}
+
+	// FileTypeInfo 根据文件内容识别的文件类型
+	FileTypeInfo struct {
+		Ext      string          // 扩展名,不含".",无法识别时为空
+		Mime     string          // MIME类型
+		Category LkkFileCategory // 类别
+	}
+
+	// fileSignature 文件头签名,magic位于offset处;match不为空时还需满足该检查
+	fileSignature struct {
+		offset   int
+		magic    string
+		match    func(buf []byte) bool
+		ext      string
+		mime     string
+		category LkkFileCategory
+	}
+
+	// ArchiveEntry 压缩包条目信息
+	ArchiveEntry struct {
+		Name           string      // 条目名称
+		Size           int64       // 原始大小
+		CompressedSize int64       // 压缩后大小,tar类格式无单独压缩的条目,值为-1
+		Mode           os.FileMode // 权限模式,含目录和符号链接标志
+		ModTime        time.Time   // 修改时间
+		Linkname       string      // 链接目标,仅tar的链接条目有值
+	}
+
+	// archiveReadCloser 读取压缩包内的文件,关闭时依次关闭各层读取器
+	archiveReadCloser struct {
+		io.Reader
+		closers []io.Closer
+	}
+
+	// archiveExtractor 压缩包解压器,负责路径检查和限制
+	archiveExtractor struct {
+		dstDir  string         // 解压目录的真实路径
+		opts    ExtractOptions // 解压选项
+		maxSize int64          // 允许写入的总字节数,-1为不限制
+		written int64          // 已写入的字节数
+		files   int            // 已解压的文件数
+	}
+
+	// AtomicWriter 原子写文件,先写入同目录的临时文件,Close时同步到磁盘再重命名为目标文件
+	AtomicWriter struct {
+		path   string      // 目标文件路径
+		perm   os.FileMode // 目标文件权限
+		backup bool        // 是否将旧文件保留为.bak
+		file   *os.File    // 临时文件
+		err    error       // 写入时的错误
+		closed bool        // 是否已关闭
+	}
+
+	// TimingWheel 分层时间轮定时器,所有定时任务由同一个协程调度
+	TimingWheel struct {
+		tick      time.Duration
+		wheelSize int64
+		levels    [][]*list.List
+		current   int64 // 已处理的tick数
+		count     int
+		startTime time.Time
+		clock     wheelClock
+		mu        sync.Mutex
+		stop      chan struct{}
+		stopOnce  sync.Once
+		exec      func(fn CallBack)
+	}
+
+	// TimerHandle 时间轮定时任务的句柄,可用于取消
+	TimerHandle struct {
+		tw       *TimingWheel
+		expire   int64 // 到期的tick数
+		interval int64 // 重复间隔的tick数,0为只执行一次
+		fn       CallBack
+		bucket   *list.List
+		elem     *list.Element
+		canceled bool
+	}
+
+	// wheelClock 时间轮使用的时钟,便于测试替换
+	wheelClock interface {
+		Now() time.Time
+		NewTicker(d time.Duration) (<-chan time.Time, func())
+	}
+
+	// realClock 系统时钟
+	realClock struct{}
+
+	// CronSchedule 已解析的cron表达式
+	CronSchedule struct {
+		second, minute, hour, dom, month, dow uint64         // 各字段允许值的位图
+		domStar, dowStar                      bool           // 日、星期字段是否为*或?
+		domLast                               []int          // L/L-n,距月末的天数
+		domLastWeekday                        bool           // LW,月末最后一个工作日
+		domWeekday                            []int          // nW,距n日最近的工作日
+		dowLast                               uint64         // nL,当月最后一个星期n
+		dowNth                                [][2]int       // n#k,当月第k个星期n
+		every                                 time.Duration  // @every的间隔
+		loc                                   *time.Location // 时区,nil为使用传入时间的时区
+	}
+
+	// CronRunner 定时任务执行器,按cron表达式执行回调
+	CronRunner struct {
+		clock    wheelClock
+		mu       sync.Mutex
+		entries  map[int]*cronEntry
+		nextID   int
+		onPanic  func(id int, err interface{})
+		stop     chan struct{}
+		stopOnce sync.Once
+		wg       sync.WaitGroup
+	}
+
+	// cronEntry 定时任务
+	cronEntry struct {
+		id       int
+		schedule *CronSchedule
+		fn       CallBack
+		next     time.Time
+		running  int32
+	}
+
+	// HttpClient 简单的http客户端,可设置默认头、cookie、重试和代理
+	HttpClient struct {
+		client      *http.Client
+		transport   *http.Transport
+		headers     http.Header
+		cookies     []*http.Cookie
+		retries     int           // 最大重试次数
+		retryWait   time.Duration // 首次重试的等待时间,之后每次翻倍
+		retryStatus []int         // 需要重试的状态码
+	}
+
+	// HttpResponse http响应,响应体已完整读取
+	HttpResponse struct {
+		StatusCode int
+		Status     string
+		Header     http.Header
+		Cookies    []*http.Cookie
+		body       []byte
+	}
+
+	// phpArray 反序列化得到的php数组,保持键的顺序,键为int64或string
+	phpArray struct {
+		keys []interface{}
+		vals []interface{}
+	}
+
+	// phpObject 反序列化得到的php对象
+	phpObject struct {
+		class string
+		phpArray
+	}
+
+	// phpField 结构体字段对应的php对象属性
+	phpField struct {
+		index []int  // 字段索引,匿名结构体展开后为多级
+		name  string // 属性名,含可见性前缀
+	}
+
+	// phpDecoder php序列化格式的解析器
+	phpDecoder struct {
+		data  []byte
+		pos   int
+		depth int
+	}
+
+	// streamEncrypter 分块认证加密的写入器
+	streamEncrypter struct {
+		w         io.Writer
+		aead      cipher.AEAD
+		header    []byte // 流头部,作为每块的附加认证数据
+		nonce     []byte
+		buf       []byte // 待加密的明文
+		out       []byte // 加密后的块
+		chunkSize int
+		counter   uint64
+		closed    bool
+		err       error
+	}
+
+	// streamDecrypter 分块认证解密的读取器
+	streamDecrypter struct {
+		r         *bufio.Reader
+		aead      cipher.AEAD
+		header    []byte
+		nonce     []byte
+		chunk     []byte // 读取的密文块
+		plain     []byte // 已解密未读取的明文
+		plainBuf  []byte
+		chunkSize int
+		counter   uint64
+		done      bool
+		err       error
+	}
+
+	// jsonWebKey JWK格式的密钥(RFC 7517),支持RSA/EC/OKP
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		D   string `json:"d,omitempty"`
+		P   string `json:"p,omitempty"`
+		Q   string `json:"q,omitempty"`
+		Dp  string `json:"dp,omitempty"`
+		Dq  string `json:"dq,omitempty"`
+		Qi  string `json:"qi,omitempty"`
+	}
+
+	// ecdsaSignature ASN.1编码的ECDSA签名
+	ecdsaSignature struct {
+		R, S *big.Int
+	}
+
+	// sm3Digest SM3散列计算,实现hash.Hash接口
+	sm3Digest struct {
+		h      [8]uint32
+		buf    [64]byte
+		nbuf   int
+		length uint64
+	}
+
+	// sm4Cipher SM4分组密码,实现cipher.Block接口
+	sm4Cipher struct {
+		rk [32]uint32
+	}
+
+	// sm2Curve SM2使用的素域椭圆曲线 y^2 = x^3 + ax + b
+	sm2Curve struct {
+		P, A, B, N, Gx, Gy *big.Int
+	}
+
+	// sm2Element 素域元素,4个64位小端序的字,以Montgomery形式表示
+	sm2Element [4]uint64
+
+	// sm2Point 射影坐标(X:Y:Z)表示的曲线点,Z为0时为无穷远点
+	sm2Point struct {
+		x, y, z sm2Element
+	}
+
+	// sm2Field 曲线的常数时间素域运算参数
+	sm2Field struct {
+		p      sm2Element // 模数p,非Montgomery形式
+		pInv   uint64     // -p^-1 mod 2^64
+		r2     sm2Element // 2^512 mod p,用于转换为Montgomery形式
+		one    sm2Element // Montgomery形式的1
+		a, b3  sm2Element // Montgomery形式的曲线参数a和3b
+		pBig   *big.Int   // 模数p
+		pMinus *big.Int   // p-2,求逆的指数
+	}
+
+	// sm2PublicKey SM2公钥
+	sm2PublicKey struct {
+		curve *sm2Curve
+		X, Y  *big.Int
+	}
+
+	// sm2PrivateKey SM2私钥
+	sm2PrivateKey struct {
+		sm2PublicKey
+		D *big.Int
+	}
+
+	// sm2PkixKey PKIX格式的SM2公钥
+	sm2PkixKey struct {
+		Algo      pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+
+	// sm2Pkcs8Key PKCS8格式的SM2私钥
+	sm2Pkcs8Key struct {
+		Version    int
+		Algo       pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}
+
+	// sm2EcPrivateKey SEC1格式的SM2私钥
+	sm2EcPrivateKey struct {
+		Version       int
+		PrivateKey    []byte
+		NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+		PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+	}
+
+	// ExecOptions 执行外部命令的选项
+	ExecOptions struct {
+		Context  context.Context   // 上下文,取消或到期时终止命令
+		Timeout  time.Duration     // 超时时间,超时后终止整个进程组;0为不限
+		Stdin    io.Reader         // 标准输入
+		Env      []string          // 环境变量,如"KEY=VALUE";为nil时继承当前进程的环境变量
+		Dir      string            // 工作目录,为空时使用当前目录
+		OnStdout func(line string) // 标准输出的逐行回调
+		OnStderr func(line string) // 标准错误的逐行回调
+	}
+
+	// ExecResult 外部命令的执行结果
+	ExecResult struct {
+		Stdout   []byte        // 标准输出
+		Stderr   []byte        // 标准错误
+		ExitCode int           // 退出码,未正常退出(如超时被终止)时为-1
+		Duration time.Duration // 执行耗时
+	}
+
+	// ExecError 执行外部命令的错误
+	ExecError struct {
+		Command  string // 命令
+		ExitCode int    // 退出码
+		Err      error  // 错误原因
+	}
 )
 
 const (
@@ -127,6 +631,90 @@ const (
 	// PKCS_SEVEN 即PKCS7
 	PKCS_SEVEN LkkPKCSType = 7
 
+	// AEAD_AES_GCM AES-256-GCM认证加密
+	AEAD_AES_GCM LkkAeadType = 1
+	// AEAD_CHACHA20_POLY1305 ChaCha20-Poly1305认证加密
+	AEAD_CHACHA20_POLY1305 LkkAeadType = 2
+
+	// RSA_PADDING_PKCS1V15 RSA签名使用PKCS#1 v1.5填充
+	RSA_PADDING_PKCS1V15 LkkRsaPadding = 1
+	// RSA_PADDING_PSS RSA签名使用PSS填充
+	RSA_PADDING_PSS LkkRsaPadding = 2
+
+	// SYMLINK_SKIP 解压时跳过符号链接
+	SYMLINK_SKIP LkkSymlinkPolicy = 0
+	// SYMLINK_INTERNAL 解压时只保留指向解压目录内的符号链接,否则报错
+	SYMLINK_INTERNAL LkkSymlinkPolicy = 1
+	// SYMLINK_ALLOW 解压时允许任意符号链接,但不会经由链接写入解压目录外
+	SYMLINK_ALLOW LkkSymlinkPolicy = 2
+
+	// EXISTING_OVERWRITE 解压时覆盖已存在的文件
+	EXISTING_OVERWRITE LkkExistingPolicy = 0
+	// EXISTING_SKIP 解压时跳过已存在的文件
+	EXISTING_SKIP LkkExistingPolicy = 1
+	// EXISTING_DENY 解压时遇到已存在的文件则报错
+	EXISTING_DENY LkkExistingPolicy = 2
+
+	// FILE_CATEGORY_UNKNOWN 文件类别-未知
+	FILE_CATEGORY_UNKNOWN LkkFileCategory = ""
+	// FILE_CATEGORY_IMAGE 文件类别-图片
+	FILE_CATEGORY_IMAGE LkkFileCategory = "image"
+	// FILE_CATEGORY_VIDEO 文件类别-视频
+	FILE_CATEGORY_VIDEO LkkFileCategory = "video"
+	// FILE_CATEGORY_AUDIO 文件类别-音频
+	FILE_CATEGORY_AUDIO LkkFileCategory = "audio"
+	// FILE_CATEGORY_ARCHIVE 文件类别-压缩包
+	FILE_CATEGORY_ARCHIVE LkkFileCategory = "archive"
+	// FILE_CATEGORY_DOCUMENT 文件类别-文档
+	FILE_CATEGORY_DOCUMENT LkkFileCategory = "document"
+	// FILE_CATEGORY_EXECUTABLE 文件类别-可执行文件
+	FILE_CATEGORY_EXECUTABLE LkkFileCategory = "executable"
+	// FILE_CATEGORY_FONT 文件类别-字体
+	FILE_CATEGORY_FONT LkkFileCategory = "font"
+
+	// PASSWORD_BCRYPT 密码散列使用bcrypt
+	PASSWORD_BCRYPT LkkPasswordAlgo = 1
+	// PASSWORD_ARGON2ID 密码散列使用argon2id
+	PASSWORD_ARGON2ID LkkPasswordAlgo = 2
+	// PASSWORD_SCRYPT 密码散列使用scrypt
+	PASSWORD_SCRYPT LkkPasswordAlgo = 3
+	// PASSWORD_PBKDF2 密码散列使用pbkdf2-sha256
+	PASSWORD_PBKDF2 LkkPasswordAlgo = 4
+
+	// PASSWORD_ARGON2_MAX_MEMORY argon2id允许的最大内存,单位KiB
+	PASSWORD_ARGON2_MAX_MEMORY = 1024 * 1024
+	// PASSWORD_ARGON2_MAX_TIME argon2id允许的最大迭代次数
+	PASSWORD_ARGON2_MAX_TIME = 64
+	// PASSWORD_ARGON2_MAX_THREADS argon2id允许的最大并行度
+	PASSWORD_ARGON2_MAX_THREADS = 64
+	// PASSWORD_SCRYPT_MAX_MEMORY scrypt允许的最大内存(128*N*R),单位字节
+	PASSWORD_SCRYPT_MAX_MEMORY = 1024 * 1024 * 1024
+	// PASSWORD_SCRYPT_MAX_P scrypt允许的最大并行度
+	PASSWORD_SCRYPT_MAX_P = 16
+	// PASSWORD_PBKDF2_MAX_ITERATIONS pbkdf2允许的最大迭代次数
+	PASSWORD_PBKDF2_MAX_ITERATIONS = 10000000
+
+	// SM2_DEFAULT_UID SM2签名默认的用户身份标识
+	SM2_DEFAULT_UID = "1234567812345678"
+
+	// STREAM_VERSION 分块加密流格式的版本号
+	STREAM_VERSION = 1
+	// STREAM_CHUNK_SIZE 分块加密流默认的明文块大小
+	STREAM_CHUNK_SIZE = 64 * 1024
+	// STREAM_CHUNK_MAX 分块加密流允许的最大明文块大小
+	STREAM_CHUNK_MAX = 16 * 1024 * 1024
+
+	// BASE58_BITCOIN 比特币使用的Base58字母表
+	BASE58_BITCOIN = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// BASE58_FLICKR Flickr使用的Base58字母表
+	BASE58_FLICKR = "123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	// BASE62_ALPHABET Base62字母表
+	BASE62_ALPHABET = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// BASE32_CROCKFORD Crockford Base32字母表
+	BASE32_CROCKFORD = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// Z85_ALPHABET ZeroMQ Z85字母表
+	Z85_ALPHABET = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
 	//默认浮点数精确小数位数
 	FLOAT_DECIMAL = 10
 
@@ -270,6 +858,51 @@ var (
 	// KDbug utilities
 	KDbug LkkDebug
 
+	// KHttp utilities
+	KHttp LkkHttp
+
+	// ErrProcessNotExist 进程不存在
+	ErrProcessNotExist = errors.New("process does not exist")
+	// ErrProcessAccess 无权限访问进程
+	ErrProcessAccess = errors.New("process access denied")
+	// ErrPidFileLocked pid文件被运行中的进程占用
+	ErrPidFileLocked = errors.New("pid file is held by a running process")
+	// ErrExecTimeout 命令执行超时
+	ErrExecTimeout = errors.New("command timed out")
+	// ErrExecCanceled 命令执行被取消
+	ErrExecCanceled = errors.New("command canceled")
+	// ErrExecExitCode 命令以非0状态退出
+	ErrExecExitCode = errors.New("command exited with non-zero status")
+
+	// ErrShutdownTimeout 退出钩子执行超时
+	ErrShutdownTimeout = errors.New("shutdown hook timed out")
+
+	// ErrAccessOwner 仅所有者或所属组有权限,当前用户不匹配
+	ErrAccessOwner = errors.New("owner or group mismatch")
+	// ErrAccessMode 文件权限位不允许访问
+	ErrAccessMode = errors.New("permission bits deny access")
+	// ErrAccessReadOnly 只读文件系统
+	ErrAccessReadOnly = errors.New("read-only file system")
+
+	// ErrBase58Checksum Base58Check校验和不匹配
+	ErrBase58Checksum = errors.New("base58check checksum mismatch")
+
+	// ErrUnsafePath 压缩包条目的路径超出解压目录
+	ErrUnsafePath = errors.New("archive entry path escapes destination")
+	// ErrUnsafeLink 压缩包条目的链接指向解压目录外
+	ErrUnsafeLink = errors.New("archive entry link escapes destination")
+	// ErrExtractLimit 解压超出字节数、文件数或压缩比限制
+	ErrExtractLimit = errors.New("archive extraction limit exceeded")
+
+	// ErrAuthFailed 认证加密的密文或附加数据校验失败
+	ErrAuthFailed = errors.New("message authentication failed")
+	// ErrSignVerify 签名校验失败
+	ErrSignVerify = errors.New("signature verification failed")
+	// ErrStreamHeader 加密流头部无效或版本不支持
+	ErrStreamHeader = errors.New("invalid encrypted stream header")
+	// ErrStreamTruncated 加密流被截断
+	ErrStreamTruncated = errors.New("encrypted stream truncated")
+
 	// KPrivCidrs 私有网段的CIDR数组
 	KPrivCidrs []*net.IPNet
 
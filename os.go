@@ -1,3 +1,1497 @@
 package kgo
 
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
 
+// procClockTicks 内核时钟频率(USER_HZ),linux下固定为100.
+const procClockTicks = 100
+
+// Error 实现error接口.
+func (e *ProcessError) Error() string {
+	return fmt.Sprintf("[%s] pid %d: %s", e.Op, e.Pid, e.Err.Error())
+}
+
+// Unwrap 返回原始错误.
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// newProcessError 创建进程错误,将系统错误归类为ErrProcessNotExist或ErrProcessAccess.
+func newProcessError(pid int, op string, err error) error {
+	if os.IsNotExist(err) {
+		err = ErrProcessNotExist
+	} else if os.IsPermission(err) {
+		err = ErrProcessAccess
+	}
+	return &ProcessError{Pid: pid, Op: op, Err: err}
+}
+
+// readProcFile 读取/proc/[pid]/下的文件.
+func readProcFile(pid int, name, op string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/%s", pid, name))
+	if err != nil {
+		return nil, newProcessError(pid, op, err)
+	}
+	return data, nil
+}
+
+// readProcStat 读取并解析/proc/[pid]/stat,返回进程名和其后的字段(从状态码开始).
+func readProcStat(pid int, op string) (string, []string, error) {
+	data, err := readProcFile(pid, "stat", op)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// 进程名可能包含空格和括号,以最后一个右括号为界
+	str := string(data)
+	start := strings.IndexByte(str, '(')
+	end := strings.LastIndexByte(str, ')')
+	if start < 0 || end < start {
+		return "", nil, &ProcessError{Pid: pid, Op: op, Err: fmt.Errorf("malformed stat: %q", str)}
+	}
+
+	fields := strings.Fields(str[end+1:])
+	if len(fields) < 22 {
+		return "", nil, &ProcessError{Pid: pid, Op: op, Err: fmt.Errorf("malformed stat: %q", str)}
+	}
+
+	return str[start+1 : end], fields, nil
+}
+
+// getBootTime 获取系统启动时间.
+func getBootTime() (time.Time, error) {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			sec, err := strconv.ParseInt(strings.TrimSpace(line[6:]), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("btime not found in /proc/stat")
+}
+
+// IsLinux 当前操作系统是否Linux.本文件中的进程函数均依赖/proc,仅在Linux下可用.
+func (ko *LkkOS) IsLinux() bool {
+	return runtime.GOOS == "linux"
+}
+
+// Pid 获取当前进程ID.
+func (ko *LkkOS) Pid() int {
+	return os.Getpid()
+}
+
+// Ppid 获取当前进程的父进程ID.
+func (ko *LkkOS) Ppid() int {
+	return os.Getppid()
+}
+
+// Getcwd 取得当前工作目录(程序可能在任务中进行多次目录切换).
+func (ko *LkkOS) Getcwd() (string, error) {
+	return os.Getwd()
+}
+
+// Chdir 改变/进入新的工作目录.
+func (ko *LkkOS) Chdir(dir string) error {
+	return os.Chdir(dir)
+}
+
+// HomeDir 获取当前用户的主目录.
+func (ko *LkkOS) HomeDir() (string, error) {
+	return os.UserHomeDir()
+}
+
+// Chmod 改变文件模式.
+func (ko *LkkOS) Chmod(filename string, mode os.FileMode) bool {
+	return os.Chmod(filename, mode) == nil
+}
+
+// IsProcessExists 进程是否存在.
+func (ko *LkkOS) IsProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	_, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
+	return err == nil
+}
+
+// GetProcessExecPath 获取进程的执行文件路径.
+func (ko *LkkOS) GetProcessExecPath(pid int) (string, error) {
+	path, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return "", newProcessError(pid, "GetProcessExecPath", err)
+	}
+	return path, nil
+}
+
+// GetProcessCmdline 获取进程的命令行参数.
+func (ko *LkkOS) GetProcessCmdline(pid int) ([]string, error) {
+	data, err := readProcFile(pid, "cmdline", "GetProcessCmdline")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) > 0 {
+		res = strings.Split(string(data), "\x00")
+	}
+
+	return res, nil
+}
+
+// GetProcessCwd 获取进程的工作目录.
+func (ko *LkkOS) GetProcessCwd(pid int) (string, error) {
+	path, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+	if err != nil {
+		return "", newProcessError(pid, "GetProcessCwd", err)
+	}
+	return path, nil
+}
+
+// GetProcessEnviron 获取进程的环境变量.
+func (ko *LkkOS) GetProcessEnviron(pid int) (map[string]string, error) {
+	data, err := readProcFile(pid, "environ", "GetProcessEnviron")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	for _, item := range bytes.Split(data, []byte{0}) {
+		if len(item) == 0 {
+			continue
+		}
+		kv := strings.SplitN(string(item), "=", 2)
+		if len(kv) == 2 {
+			res[kv[0]] = kv[1]
+		} else {
+			res[kv[0]] = ""
+		}
+	}
+
+	return res, nil
+}
+
+// GetProcessStartTime 获取进程的启动时间.
+func (ko *LkkOS) GetProcessStartTime(pid int) (time.Time, error) {
+	_, fields, err := readProcStat(pid, "GetProcessStartTime")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return procStartTime(pid, fields, "GetProcessStartTime")
+}
+
+// procStartTime 根据stat字段计算进程启动时间.
+func procStartTime(pid int, fields []string, op string) (time.Time, error) {
+	btime, err := getBootTime()
+	if err != nil {
+		return time.Time{}, &ProcessError{Pid: pid, Op: op, Err: err}
+	}
+
+	// starttime为第22个字段,单位为时钟滴答
+	ticks, _ := strconv.ParseUint(fields[19], 10, 64)
+	return btime.Add(time.Duration(ticks) * time.Second / procClockTicks), nil
+}
+
+// GetProcessState 获取进程的状态码,如R(运行)/S(睡眠)/D(不可中断)/Z(僵尸)/T(停止).
+func (ko *LkkOS) GetProcessState(pid int) (string, error) {
+	_, fields, err := readProcStat(pid, "GetProcessState")
+	if err != nil {
+		return "", err
+	}
+	return fields[0], nil
+}
+
+// GetProcessThreads 获取进程的线程数.
+func (ko *LkkOS) GetProcessThreads(pid int) (int, error) {
+	_, fields, err := readProcStat(pid, "GetProcessThreads")
+	if err != nil {
+		return 0, err
+	}
+	num, _ := strconv.Atoi(fields[17])
+	return num, nil
+}
+
+// GetProcessMemory 获取进程的内存占用;rss为常驻内存,vsz为虚拟内存,单位字节.
+func (ko *LkkOS) GetProcessMemory(pid int) (rss, vsz uint64, err error) {
+	var fields []string
+	_, fields, err = readProcStat(pid, "GetProcessMemory")
+	if err != nil {
+		return
+	}
+
+	vsz, _ = strconv.ParseUint(fields[20], 10, 64)
+	rss, _ = strconv.ParseUint(fields[21], 10, 64)
+	rss *= uint64(os.Getpagesize())
+	return
+}
+
+// GetProcessFdNum 获取进程打开的文件描述符数量.
+func (ko *LkkOS) GetProcessFdNum(pid int) (int, error) {
+	dir, err := os.Open(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return 0, newProcessError(pid, "GetProcessFdNum", err)
+	}
+	defer func() {
+		_ = dir.Close()
+	}()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return 0, newProcessError(pid, "GetProcessFdNum", err)
+	}
+
+	return len(names), nil
+}
+
+// GetProcessInfo 获取进程信息.无权限读取的项(如其他用户进程的exe/cwd/fd)将置空.
+func (ko *LkkOS) GetProcessInfo(pid int) (*ProcessInfo, error) {
+	name, fields, err := readProcStat(pid, "GetProcessInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ProcessInfo{
+		Pid:   pid,
+		Name:  name,
+		State: fields[0],
+	}
+	res.Ppid, _ = strconv.Atoi(fields[1])
+	res.Threads, _ = strconv.Atoi(fields[17])
+	res.Vsz, _ = strconv.ParseUint(fields[20], 10, 64)
+	res.Rss, _ = strconv.ParseUint(fields[21], 10, 64)
+	res.Rss *= uint64(os.Getpagesize())
+	res.StartTime, _ = procStartTime(pid, fields, "GetProcessInfo")
+
+	res.Exe, _ = ko.GetProcessExecPath(pid)
+	res.Cmdline, _ = ko.GetProcessCmdline(pid)
+	res.Cwd, _ = ko.GetProcessCwd(pid)
+	res.FdNum, _ = ko.GetProcessFdNum(pid)
+
+	return res, nil
+}
+
+// GetProcesses 获取所有进程的信息.遍历过程中已退出的进程将被忽略.
+func (ko *LkkOS) GetProcesses() ([]*ProcessInfo, error) {
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*ProcessInfo, 0, len(dirs))
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+
+		info, err := ko.GetProcessInfo(pid)
+		if err != nil {
+			continue
+		}
+		res = append(res, info)
+	}
+
+	return res, nil
+}
+
+// socketStates /proc/net/tcp中的连接状态码.
+var socketStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+// parseSocketAddr 解析/proc/net/*中十六进制的"IP:端口".IP按32位分组,每组为本机字节序(小端).
+func parseSocketAddr(str string) (string, int, error) {
+	pos := strings.IndexByte(str, ':')
+	if pos < 0 {
+		return "", 0, fmt.Errorf("malformed socket address: %s", str)
+	}
+
+	port, err := strconv.ParseUint(str[pos+1:], 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	raw, err := hex.DecodeString(str[:pos])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("malformed socket address: %s", str)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	return ip.String(), int(port), nil
+}
+
+// readNetSockets 读取/proc/net/下某协议(tcp/tcp6/udp/udp6)的套接字列表.
+func readNetSockets(protocol string) ([]*SocketInfo, error) {
+	data, err := ioutil.ReadFile("/proc/net/" + protocol)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// 未启用该协议,如禁用了ipv6
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var res []*SocketInfo
+	isUdp := strings.HasPrefix(protocol, "udp")
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+
+		item := &SocketInfo{Protocol: protocol}
+		item.LocalIP, item.LocalPort, err = parseSocketAddr(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		item.RemoteIP, item.RemotePort, err = parseSocketAddr(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		item.State = socketStates[fields[3]]
+		if isUdp && fields[3] == "07" {
+			// UDP无连接状态,已绑定但未连接的视为未连接(UNCONN)
+			item.State = "UNCONN"
+		}
+
+		item.Uid, _ = strconv.Atoi(fields[7])
+		item.Inode, _ = strconv.ParseUint(fields[9], 10, 64)
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+// getSocketPids 扫描/proc/*/fd,获取套接字inode与进程ID的映射.须root权限才能查看其他用户的进程.
+func getSocketPids() map[uint64]int {
+	res := make(map[uint64]int)
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/[0-9]*")
+	for _, fd := range fds {
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(link[8:len(link)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if _, ok := res[inode]; !ok {
+			res[inode], _ = strconv.Atoi(strings.Split(fd, "/")[2])
+		}
+	}
+
+	return res
+}
+
+// GetSockets 获取套接字列表,包括所属进程.protocols为协议,可选tcp/tcp6/udp/udp6,默认全部.
+func (ko *LkkOS) GetSockets(protocols ...string) ([]*SocketInfo, error) {
+	if len(protocols) == 0 {
+		protocols = []string{"tcp", "tcp6", "udp", "udp6"}
+	}
+
+	var res []*SocketInfo
+	for _, protocol := range protocols {
+		switch protocol {
+		case "tcp", "tcp6", "udp", "udp6":
+		default:
+			return nil, fmt.Errorf("[GetSockets] unsupported protocol: %s", protocol)
+		}
+
+		items, err := readNetSockets(protocol)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+	}
+
+	if len(res) > 0 {
+		pids := getSocketPids()
+		exes := make(map[int]string)
+		for _, item := range res {
+			if pid, ok := pids[item.Inode]; ok && item.Inode > 0 {
+				item.Pid = pid
+				if _, ok := exes[pid]; !ok {
+					exes[pid] = getProcessPathByPid(pid)
+				}
+				item.Exe = exes[pid]
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// GetListenSockets 获取正在监听的套接字列表(TCP为LISTEN状态,UDP为未连接状态).
+func (ko *LkkOS) GetListenSockets() ([]*SocketInfo, error) {
+	all, err := ko.GetSockets()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*SocketInfo
+	for _, item := range all {
+		if item.State == "LISTEN" || item.State == "UNCONN" {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// GetEstablishedSockets 获取已建立连接的套接字列表.
+func (ko *LkkOS) GetEstablishedSockets() ([]*SocketInfo, error) {
+	all, err := ko.GetSockets()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*SocketInfo
+	for _, item := range all {
+		if item.State == "ESTABLISHED" {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// GetPidByPort 获取监听该端口的进程ID(先查TCP后查UDP),未找到时返回0.查看其他用户的进程须root权限.
+func (ko *LkkOS) GetPidByPort(port int) (pid int) {
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		items, _ := readNetSockets(protocol)
+		for _, item := range items {
+			if item.LocalPort != port || item.Inode == 0 || (item.State != "LISTEN" && item.State != "UNCONN") {
+				continue
+			}
+
+			pid = getPidByInode(fmt.Sprintf(`socket:\[%d\]`, item.Inode), nil)
+			if pid > 0 {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// readPidFromFile 读取pid文件中的进程ID.
+func readPidFromFile(fpath string) (int, error) {
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %s", fpath, err.Error())
+	}
+
+	return pid, nil
+}
+
+// isSameExecutable 进程pid的执行文件是否与当前进程相同.
+func isSameExecutable(pid int) bool {
+	exe := strings.TrimSuffix(getProcessPathByPid(pid), " (deleted)")
+	self := strings.TrimSuffix(getProcessPathByPid(os.Getpid()), " (deleted)")
+	return exe != "" && exe == self
+}
+
+// CheckPidFile 检查pid文件,返回其中的进程ID及该进程是否仍在运行.
+// 文件被其他进程加锁,或pid对应的进程存在且与当前进程为同一执行文件时,视为运行中;否则为过期的pid文件.
+func (ko *LkkOS) CheckPidFile(fpath string) (pid int, running bool, err error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	pid, _ = readPidFromFile(fpath)
+	lockErr := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if lockErr == syscall.EWOULDBLOCK {
+		running = true
+		return
+	} else if lockErr == nil {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}
+
+	running = pid > 0 && pid != os.Getpid() && ko.IsProcessExists(pid) && isSameExecutable(pid)
+	return
+}
+
+// NewPidFile 创建pid文件并加锁.
+// 若已存在的pid文件属于运行中的进程,返回包含ErrPidFileLocked的ProcessError;
+// 若为过期文件(进程不存在或为其他程序),则加锁后改写为当前进程ID.
+func (ko *LkkOS) NewPidFile(fpath string) (*PidFile, error) {
+	fpath = KFile.AbsPath(fpath)
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return nil, err
+	}
+
+	pid := os.Getpid()
+	for {
+		// 锁加在路径当前对应的文件上,多个进程竞争同一过期文件时只有一个能成功
+		f, err := os.OpenFile(fpath, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == syscall.EWOULDBLOCK {
+			_ = f.Close()
+			oldPid, _ := readPidFromFile(fpath)
+			return nil, &ProcessError{Pid: oldPid, Op: "NewPidFile", Err: ErrPidFileLocked}
+		} else if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		// 加锁前文件已被持有者删除,重新创建
+		if !isSameFile(f, fpath) {
+			_ = f.Close()
+			continue
+		}
+
+		// 未加锁但进程仍在运行,如旧版本创建的pid文件
+		if oldPid, err := readPidFromFile(fpath); err == nil && oldPid != pid && ko.IsProcessExists(oldPid) && isSameExecutable(oldPid) {
+			_ = f.Close()
+			return nil, &ProcessError{Pid: oldPid, Op: "NewPidFile", Err: ErrPidFileLocked}
+		}
+
+		if err = f.Truncate(0); err == nil {
+			_, err = f.WriteAt([]byte(fmt.Sprintf("%d\n", pid)), 0)
+		}
+		if err == nil {
+			err = f.Sync()
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		return &PidFile{Path: fpath, Pid: pid, file: f}, nil
+	}
+}
+
+// isSameFile 已打开的文件f与路径fpath是否同一文件.
+func isSameFile(f *os.File, fpath string) bool {
+	fi1, err1 := f.Stat()
+	fi2, err2 := os.Stat(fpath)
+	return err1 == nil && err2 == nil && os.SameFile(fi1, fi2)
+}
+
+// Remove 释放锁并删除pid文件;若文件已被其他进程替换,则只释放锁不删除.
+func (pf *PidFile) Remove() error {
+	if pf.file == nil {
+		return nil
+	}
+
+	var err error
+	if isSameFile(pf.file, pf.Path) {
+		err = os.Remove(pf.Path)
+	}
+	_ = syscall.Flock(int(pf.file.Fd()), syscall.LOCK_UN)
+	_ = pf.file.Close()
+	pf.file = nil
+
+	return err
+}
+
+// GoMemory 获取当前go程序向系统申请的内存,字节.
+func (ko *LkkOS) GoMemory() uint64 {
+	var stat runtime.MemStats
+	runtime.ReadMemStats(&stat)
+	return stat.Sys
+}
+
+// readCpuTimes 读取/proc/stat中各CPU的时间片,第一项为总计,其后为各核心;每项为[总时间,空闲时间].
+func readCpuTimes() ([][2]uint64, error) {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+
+	var res [][2]uint64
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "cpu") {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal guest guest_nice
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		var total, idle uint64
+		for i, field := range fields[1:] {
+			// guest已计入user,不重复累加
+			if i >= 8 {
+				break
+			}
+			num, _ := strconv.ParseUint(field, 10, 64)
+			total += num
+			if i == 3 || i == 4 {
+				idle += num
+			}
+		}
+		res = append(res, [2]uint64{total, idle})
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("cpu not found in /proc/stat")
+	}
+
+	return res, nil
+}
+
+// cpuPercent 根据前后两次采样计算使用率.
+func cpuPercent(before, after [2]uint64) float64 {
+	if after[0] <= before[0] {
+		return 0
+	}
+
+	//内核统计的空闲时间(含iowait)偶尔会回退,差值须避免无符号下溢
+	total := after[0] - before[0]
+	var idle uint64
+	if after[1] > before[1] {
+		idle = after[1] - before[1]
+	}
+	if total <= idle {
+		return 0
+	}
+
+	return KNum.RoundPlus(float64(total-idle)/float64(total)*100, 2)
+}
+
+// CpuUsage 获取CPU使用率(总体和各核心).
+// interval为采样间隔,如time.Second;为0时计算开机以来的平均使用率.
+func (ko *LkkOS) CpuUsage(interval time.Duration) (*SysCpuUsage, error) {
+	var first [][2]uint64
+	second, err := readCpuTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		first = second
+		time.Sleep(interval)
+		second, err = readCpuTimes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &SysCpuUsage{}
+	for i, item := range second {
+		var before [2]uint64
+		if i < len(first) {
+			before = first[i]
+		}
+
+		percent := cpuPercent(before, item)
+		if i == 0 {
+			res.Percent = percent
+		} else {
+			res.Cores = append(res.Cores, percent)
+		}
+	}
+
+	return res, nil
+}
+
+// MemoryUsage 获取系统内存和交换区的使用情况.
+func (ko *LkkOS) MemoryUsage() (*SysMemInfo, error) {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		num, _ := strconv.ParseUint(fields[1], 10, 64)
+		if len(fields) == 3 && fields[2] == "kB" {
+			num *= 1024
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = num
+	}
+
+	res := &SysMemInfo{
+		Total:     values["MemTotal"],
+		Free:      values["MemFree"],
+		Available: values["MemAvailable"],
+		Buffers:   values["Buffers"],
+		Cached:    values["Cached"],
+		SwapTotal: values["SwapTotal"],
+		SwapFree:  values["SwapFree"],
+	}
+
+	// 3.14以下内核无MemAvailable
+	if _, ok := values["MemAvailable"]; !ok {
+		res.Available = res.Free + res.Buffers + res.Cached
+	}
+	if res.Total > res.Available {
+		res.Used = res.Total - res.Available
+	}
+	if res.Total > 0 {
+		res.UsedPercent = KNum.RoundPlus(float64(res.Used)/float64(res.Total)*100, 2)
+	}
+
+	if res.SwapTotal > res.SwapFree {
+		res.SwapUsed = res.SwapTotal - res.SwapFree
+	}
+	if res.SwapTotal > 0 {
+		res.SwapUsedPercent = KNum.RoundPlus(float64(res.SwapUsed)/float64(res.SwapTotal)*100, 2)
+	}
+
+	return res, nil
+}
+
+// LoadAverage 获取系统平均负载.
+func (ko *LkkOS) LoadAverage() (*SysLoadAvg, error) {
+	data, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil, err
+	}
+
+	// 如: 0.42 0.97 1.30 1/71 9572
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("malformed /proc/loadavg: %q", string(data))
+	}
+
+	res := &SysLoadAvg{}
+	res.Load1, _ = strconv.ParseFloat(fields[0], 64)
+	res.Load5, _ = strconv.ParseFloat(fields[1], 64)
+	res.Load15, _ = strconv.ParseFloat(fields[2], 64)
+	if procs := strings.Split(fields[3], "/"); len(procs) == 2 {
+		res.Running, _ = strconv.Atoi(procs[0])
+		res.Total, _ = strconv.Atoi(procs[1])
+	}
+
+	return res, nil
+}
+
+// Uptime 获取系统已运行时长.
+func (ko *LkkOS) Uptime() (time.Duration, error) {
+	data, err := ioutil.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed /proc/uptime: %q", string(data))
+	}
+
+	sec, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(sec * float64(time.Second)), nil
+}
+
+// unescapeMountPath 还原/proc/mounts中被转义为八进制的字符,如空格为\040 .
+func unescapeMountPath(str string) string {
+	if !strings.Contains(str, `\`) {
+		return str
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+3 < len(str) {
+			if num, err := strconv.ParseUint(str[i+1:i+4], 8, 8); err == nil {
+				buf.WriteByte(byte(num))
+				i += 3
+				continue
+			}
+		}
+		buf.WriteByte(str[i])
+	}
+
+	return buf.String()
+}
+
+// readMounts 读取/proc/mounts,每项为[设备,挂载点,文件系统类型].
+func readMounts() ([][3]string, error) {
+	data, err := ioutil.ReadFile("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+
+	var res [][3]string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		res = append(res, [3]string{unescapeMountPath(fields[0]), unescapeMountPath(fields[1]), fields[2]})
+	}
+
+	return res, nil
+}
+
+// DiskUsage 获取路径所在分区的使用情况.
+func (ko *LkkOS) DiskUsage(fpath string) (*SysDiskInfo, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(fpath, &stat); err != nil {
+		return nil, err
+	}
+
+	// freebsd上Bavail和Ffree为int64,普通用户超用保留空间时可能为负数
+	avail, ffree := int64(stat.Bavail), int64(stat.Ffree)
+	if avail < 0 {
+		avail = 0
+	}
+	if ffree < 0 {
+		ffree = 0
+	}
+
+	bsize := uint64(stat.Bsize)
+	res := &SysDiskInfo{
+		Mountpoint: fpath,
+		Total:      stat.Blocks * bsize,
+		Free:       stat.Bfree * bsize,
+		Avail:      uint64(avail) * bsize,
+		Inodes:     stat.Files,
+		InodesFree: uint64(ffree),
+	}
+	res.Used = res.Total - res.Free
+	if res.Used+res.Avail > 0 {
+		res.UsedPercent = KNum.RoundPlus(float64(res.Used)/float64(res.Used+res.Avail)*100, 2)
+	}
+
+	// 查找路径所属的挂载点(最长前缀匹配)
+	if mounts, err := readMounts(); err == nil {
+		absPath := KFile.AbsPath(fpath)
+		var matchLen = -1
+		for _, mount := range mounts {
+			point := mount[1]
+			if (absPath == point || strings.HasPrefix(absPath, strings.TrimRight(point, "/")+"/")) && len(point) >= matchLen {
+				matchLen = len(point)
+				res.Device, res.Mountpoint, res.Fstype = mount[0], point, mount[2]
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// DiskUsages 获取所有已挂载分区的使用情况,忽略容量为0的虚拟文件系统(如proc/sysfs).
+func (ko *LkkOS) DiskUsages() ([]*SysDiskInfo, error) {
+	mounts, err := readMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*SysDiskInfo
+	points := make(map[string]bool)
+	for _, mount := range mounts {
+		if _, ok := points[mount[1]]; ok {
+			continue
+		}
+
+		info, err := ko.DiskUsage(mount[1])
+		if err != nil || info.Total == 0 {
+			continue
+		}
+
+		points[mount[1]] = true
+		info.Device, info.Mountpoint, info.Fstype = mount[0], mount[1], mount[2]
+		res = append(res, info)
+	}
+
+	return res, nil
+}
+
+// Error 实现error接口.
+func (e *ExecError) Error() string {
+	if e.Err == ErrExecExitCode {
+		return fmt.Sprintf("[Exec] %s: %s %d", e.Command, e.Err.Error(), e.ExitCode)
+	}
+	return fmt.Sprintf("[Exec] %s: %s", e.Command, e.Err.Error())
+}
+
+// Unwrap 返回原始错误.
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// execLineWriter 收集命令的输出,并按行回调.
+type execLineWriter struct {
+	buf     bytes.Buffer
+	pending []byte
+	fn      func(line string)
+}
+
+// Write 实现io.Writer接口.
+func (w *execLineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	if w.fn != nil {
+		w.pending = append(w.pending, p...)
+		for {
+			pos := bytes.IndexByte(w.pending, '\n')
+			if pos < 0 {
+				break
+			}
+			w.fn(strings.TrimRight(string(w.pending[:pos]), "\r"))
+			w.pending = w.pending[pos+1:]
+		}
+	}
+
+	return len(p), nil
+}
+
+// flush 回调最后不以换行结尾的内容.
+func (w *execLineWriter) flush() {
+	if w.fn != nil && len(w.pending) > 0 {
+		w.fn(string(w.pending))
+		w.pending = nil
+	}
+}
+
+// execWaitDelay 命令退出或被终止后,等待其输出读取完毕的最长时间.
+const execWaitDelay = 5 * time.Second
+
+// runCommand 执行命令,超时或取消时终止其整个进程组.
+func runCommand(cmd *exec.Cmd, command string, opts []*ExecOptions) (*ExecResult, error) {
+	opt := &ExecOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		opt = opts[0]
+	}
+
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+
+	res := &ExecResult{ExitCode: -1}
+
+	// 自行创建管道并读取输出,使后台子进程持有管道时也能在execWaitDelay后返回(同go1.20的cmd.WaitDelay)
+	var pipes []*os.File
+	closePipes := func() {
+		for _, f := range pipes {
+			_ = f.Close()
+		}
+	}
+	for i := 0; i < 3; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closePipes()
+			return res, &ExecError{Command: command, ExitCode: -1, Err: err}
+		}
+		pipes = append(pipes, r, w)
+	}
+	stdinR, stdinW, stdoutR, stdoutW, stderrR, stderrW := pipes[0], pipes[1], pipes[2], pipes[3], pipes[4], pipes[5]
+
+	stdout := &execLineWriter{fn: opt.OnStdout}
+	stderr := &execLineWriter{fn: opt.OnStderr}
+	if opt.Stdin != nil {
+		cmd.Stdin = stdinR
+	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+	cmd.Dir = opt.Dir
+	if opt.Env != nil {
+		cmd.Env = opt.Env
+	}
+	// 使用独立的进程组,以便终止命令派生的子进程
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	start := time.Now()
+	err := cmd.Start()
+	// 子进程已持有管道的一端,父进程中的副本须关闭,否则读取不会结束
+	_ = stdinR.Close()
+	_ = stdoutW.Close()
+	_ = stderrW.Close()
+	if err != nil {
+		closePipes()
+		return res, &ExecError{Command: command, ExitCode: -1, Err: err}
+	}
+
+	if opt.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(stdinW, opt.Stdin)
+			_ = stdinW.Close()
+		}()
+	} else {
+		_ = stdinW.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		_, _ = io.Copy(stdout, stdoutR)
+		wg.Done()
+	}()
+	go func() {
+		_, _ = io.Copy(stderr, stderrR)
+		wg.Done()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var killed bool
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		err = <-done
+		// 若终止前命令已正常退出,仍视为正常结束
+		killed = !cmd.ProcessState.Exited()
+	}
+
+	// 等待输出读取完毕,超时则关闭管道
+	copied := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(copied)
+	}()
+	timer := time.NewTimer(execWaitDelay)
+	select {
+	case <-copied:
+	case <-timer.C:
+	}
+	timer.Stop()
+	closePipes()
+	<-copied
+
+	res.Duration = time.Since(start)
+	stdout.flush()
+	stderr.flush()
+	res.Stdout = stdout.buf.Bytes()
+	res.Stderr = stderr.buf.Bytes()
+	res.ExitCode = cmd.ProcessState.ExitCode()
+
+	if killed {
+		reason := ErrExecTimeout
+		if ctx.Err() == context.Canceled {
+			reason = ErrExecCanceled
+		}
+		return res, &ExecError{Command: command, ExitCode: res.ExitCode, Err: reason}
+	} else if res.ExitCode > 0 {
+		return res, &ExecError{Command: command, ExitCode: res.ExitCode, Err: ErrExecExitCode}
+	} else if err != nil {
+		return res, &ExecError{Command: command, ExitCode: res.ExitCode, Err: err}
+	}
+
+	return res, nil
+}
+
+// Exec 直接执行外部命令(不经过shell).name为命令,args为参数,opts为可选的执行选项.
+// 命令以非0状态退出时返回包含ErrExecExitCode的ExecError,超时为ErrExecTimeout,上下文被取消为ErrExecCanceled;出错时仍返回已收集的结果.
+func (ko *LkkOS) Exec(name string, args []string, opts ...*ExecOptions) (*ExecResult, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	return runCommand(exec.Command(name, args...), command, opts)
+}
+
+// System 通过/bin/sh -c执行shell命令,opts为可选的执行选项.返回值同Exec.
+func (ko *LkkOS) System(command string, opts ...*ExecOptions) (*ExecResult, error) {
+	return runCommand(exec.Command("/bin/sh", "-c", command), command, opts)
+}
+
+func init() {
+	// RFC1918,RFC6598(运营商级NAT),RFC4193
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, block, _ := net.ParseCIDR(cidr)
+		KPrivCidrs = append(KPrivCidrs, block)
+	}
+}
+
+// GetNetInterfaces 获取本机的网络接口列表.
+func (ko *LkkOS) GetNetInterfaces() ([]NetInterfaceInfo, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]NetInterfaceInfo, 0, len(ifaces))
+	for _, iface := range ifaces {
+		info := NetInterfaceInfo{
+			Index: iface.Index,
+			Name:  iface.Name,
+			Mac:   iface.HardwareAddr.String(),
+			Mtu:   iface.MTU,
+			Flags: []string{},
+			Addrs: []string{},
+		}
+		if iface.Flags != 0 {
+			info.Flags = strings.Split(iface.Flags.String(), "|")
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+		res = append(res, info)
+	}
+
+	return res, nil
+}
+
+// getOutboundIP 通过UDP"连接"获取路由选择的本地地址,不会实际发送数据.
+func getOutboundIP(network, addr string) (string, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// GetOutboundIPv4 获取本机访问外网时使用的IPv4地址(不发送数据).
+func (ko *LkkOS) GetOutboundIPv4() (string, error) {
+	return getOutboundIP("udp4", "8.8.8.8:80")
+}
+
+// GetOutboundIPv6 获取本机访问外网时使用的IPv6地址(不发送数据).
+func (ko *LkkOS) GetOutboundIPv6() (string, error) {
+	return getOutboundIP("udp6", "[2001:4860:4860::8888]:80")
+}
+
+// GetLocalIPs 获取本机接口上的私有IP和公网IP,不含回环、链路本地和未启用接口的地址.
+func (ko *LkkOS) GetLocalIPs() (privates, publics []string, err error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return
+	}
+
+	privates, publics = []string{}, []string{}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || !ipnet.IP.IsGlobalUnicast() {
+				continue
+			}
+
+			if isPrivateIP(ipnet.IP) {
+				privates = append(privates, ipnet.IP.String())
+			} else {
+				publics = append(publics, ipnet.IP.String())
+			}
+		}
+	}
+
+	return
+}
+
+// isPrivateIP 是否处于KPrivCidrs私有网段内.
+func isPrivateIP(ip net.IP) bool {
+	for _, block := range KPrivCidrs {
+		if block.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivateIP 是否私有网段的IP(见KPrivCidrs).
+func (ko *LkkOS) IsPrivateIP(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && isPrivateIP(ip)
+}
+
+// IsPublicIP 是否公网IP,即非私有、回环、链路本地、组播的单播地址.
+func (ko *LkkOS) IsPublicIP(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && ip.IsGlobalUnicast() && !isPrivateIP(ip)
+}
+
+// IsLoopbackIP 是否回环IP,如127.0.0.1或::1.
+func (ko *LkkOS) IsLoopbackIP(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && ip.IsLoopback()
+}
+
+// IsLinkLocalIP 是否链路本地IP,如169.254.0.0/16或fe80::/10.
+func (ko *LkkOS) IsLinkLocalIP(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast())
+}
+
+// IsMulticastIP 是否组播IP.
+func (ko *LkkOS) IsMulticastIP(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && ip.IsMulticast()
+}
+
+// NewGracefulShutdown 创建优雅退出助手,并开始监听SIGINT/SIGTERM/SIGHUP信号.
+// SIGHUP执行重载钩子,SIGINT/SIGTERM执行退出钩子;退出过程中再次收到SIGINT/SIGTERM则强制以状态1退出.
+func (ko *LkkOS) NewGracefulShutdown() *GracefulShutdown {
+	ctx, cancel := context.WithCancel(context.Background())
+	gs := &GracefulShutdown{
+		ctx:    ctx,
+		cancel: cancel,
+		sigCh:  make(chan os.Signal, 2),
+		done:   make(chan struct{}),
+		exit:   os.Exit,
+	}
+
+	signal.Notify(gs.sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go gs.listen()
+
+	return gs
+}
+
+// listen 处理收到的信号.
+func (gs *GracefulShutdown) listen() {
+	for {
+		select {
+		case sig := <-gs.sigCh:
+			if sig == syscall.SIGHUP {
+				gs.reload()
+			} else if !gs.start() {
+				signal.Stop(gs.sigCh)
+				gs.exit(1)
+				return
+			}
+		case <-gs.done:
+			signal.Stop(gs.sigCh)
+			return
+		}
+	}
+}
+
+// start 开始退出流程;若已在退出中则返回false.
+func (gs *GracefulShutdown) start() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.stopping {
+		return false
+	}
+
+	gs.stopping = true
+	go gs.run()
+	return true
+}
+
+// run 取消上下文并按注册顺序执行退出钩子.
+func (gs *GracefulShutdown) run() {
+	gs.cancel()
+
+	gs.mu.Lock()
+	hooks := append([]shutdownHook(nil), gs.hooks...)
+	gs.mu.Unlock()
+
+	for _, hook := range hooks {
+		if err := runShutdownHook(hook); err != nil && gs.err == nil {
+			gs.err = err
+		}
+	}
+	close(gs.done)
+}
+
+// runShutdownHook 执行单个退出钩子;超时后不再等待,继续执行后续钩子.
+func runShutdownHook(hook shutdownHook) error {
+	res := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res <- fmt.Errorf("%s: panic: %v", hook.name, r)
+			}
+		}()
+		hook.fn()
+		res <- nil
+	}()
+
+	if hook.timeout <= 0 {
+		return <-res
+	}
+
+	timer := time.NewTimer(hook.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-res:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%s: %w", hook.name, ErrShutdownTimeout)
+	}
+}
+
+// reload 执行重载钩子,退出过程中忽略.
+func (gs *GracefulShutdown) reload() {
+	gs.mu.Lock()
+	if gs.stopping {
+		gs.mu.Unlock()
+		return
+	}
+	reloads := append([]CallBack(nil), gs.reloads...)
+	gs.mu.Unlock()
+
+	for _, fn := range reloads {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			fn()
+		}()
+	}
+}
+
+// OnShutdown 注册退出钩子,按注册顺序执行.name用于错误信息,timeout为该钩子的超时时间,0为不限.
+func (gs *GracefulShutdown) OnShutdown(name string, fn CallBack, timeout time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.hooks = append(gs.hooks, shutdownHook{name: name, fn: fn, timeout: timeout})
+}
+
+// OnReload 注册收到SIGHUP时执行的重载钩子.
+func (gs *GracefulShutdown) OnReload(fn CallBack) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.reloads = append(gs.reloads, fn)
+}
+
+// Context 获取上下文,开始退出时被取消.
+func (gs *GracefulShutdown) Context() context.Context {
+	return gs.ctx
+}
+
+// Shutdown 主动开始退出,并等待所有退出钩子执行完毕.
+func (gs *GracefulShutdown) Shutdown() error {
+	gs.start()
+	return gs.Wait()
+}
+
+// Wait 阻塞直到退出钩子执行完毕,返回第一个失败(超时或panic)的钩子错误.
+func (gs *GracefulShutdown) Wait() error {
+	<-gs.done
+	return gs.err
+}
+
+// GetCurrentUser 获取当前用户.
+func (ko *LkkOS) GetCurrentUser() (*user.User, error) {
+	return user.Current()
+}
+
+// Uid 获取当前进程的用户ID.
+func (ko *LkkOS) Uid() int {
+	return os.Getuid()
+}
+
+// Euid 获取当前进程的有效用户ID.
+func (ko *LkkOS) Euid() int {
+	return os.Geteuid()
+}
+
+// Gid 获取当前进程的组ID.
+func (ko *LkkOS) Gid() int {
+	return os.Getgid()
+}
+
+// Egid 获取当前进程的有效组ID.
+func (ko *LkkOS) Egid() int {
+	return os.Getegid()
+}
+
+// IsRoot 当前进程是否以root身份运行.
+func (ko *LkkOS) IsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// GetUidByName 根据用户名获取用户ID.
+func (ko *LkkOS) GetUidByName(name string) (int, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// GetUserNameByUid 根据用户ID获取用户名.
+func (ko *LkkOS) GetUserNameByUid(uid int) (string, error) {
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+// GetGidByName 根据组名获取组ID.
+func (ko *LkkOS) GetGidByName(name string) (int, error) {
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// GetGroupNameByGid 根据组ID获取组名.
+func (ko *LkkOS) GetGroupNameByGid(gid int) (string, error) {
+	g, err := user.LookupGroupId(strconv.Itoa(gid))
+	if err != nil {
+		return "", err
+	}
+	return g.Name, nil
+}
@@ -1,2 +1,1124 @@
 package kgo
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsLinux(t *testing.T) {
+	if KOS.IsLinux() != (runtime.GOOS == "linux") {
+		t.Error("IsLinux fail")
+		return
+	}
+}
+
+func TestPidPpid(t *testing.T) {
+	if KOS.Pid() != os.Getpid() || KOS.Ppid() != os.Getppid() {
+		t.Error("Pid/Ppid fail")
+		return
+	}
+}
+
+func BenchmarkPid(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		KOS.Pid()
+	}
+}
+
+func TestGetcwdChdir(t *testing.T) {
+	dir, err := KOS.Getcwd()
+	if err != nil || dir == "" {
+		t.Error("Getcwd fail")
+		return
+	}
+
+	err = KOS.Chdir("./testdata")
+	if err != nil {
+		t.Error("Chdir fail")
+		return
+	}
+	_ = KOS.Chdir(dir)
+}
+
+func BenchmarkGetcwd(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.Getcwd()
+	}
+}
+
+func TestHomeDir(t *testing.T) {
+	res, err := KOS.HomeDir()
+	if err != nil || res == "" {
+		t.Error("HomeDir fail")
+		return
+	}
+}
+
+func TestChmod(t *testing.T) {
+	filename := "./testdata/chmod"
+	KFile.Touch(filename, 0)
+	if !KOS.Chmod(filename, 0755) {
+		t.Error("Chmod fail")
+		return
+	}
+	KOS.Chmod("./testdata/nothing", 0755)
+}
+
+func TestIsProcessExists(t *testing.T) {
+	if !KOS.IsProcessExists(os.Getpid()) {
+		t.Error("IsProcessExists fail")
+		return
+	}
+	if KOS.IsProcessExists(-1) || KOS.IsProcessExists(INT_MAX) {
+		t.Error("IsProcessExists fail")
+		return
+	}
+}
+
+func BenchmarkIsProcessExists(b *testing.B) {
+	b.ResetTimer()
+	pid := os.Getpid()
+	for i := 0; i < b.N; i++ {
+		KOS.IsProcessExists(pid)
+	}
+}
+
+func TestGetProcessExecPath(t *testing.T) {
+	pid := os.Getpid()
+	exe, _ := os.Executable()
+	res, err := KOS.GetProcessExecPath(pid)
+	if err != nil || res != exe || res != getProcessPathByPid(pid) {
+		t.Error("GetProcessExecPath fail")
+		return
+	}
+
+	_, err = KOS.GetProcessExecPath(INT_MAX)
+	if !errors.Is(err, ErrProcessNotExist) {
+		t.Error("GetProcessExecPath fail")
+		return
+	}
+
+	var perr *ProcessError
+	if !errors.As(err, &perr) || perr.Pid != INT_MAX || perr.Op != "GetProcessExecPath" || perr.Error() == "" {
+		t.Error("ProcessError fail")
+		return
+	}
+}
+
+func TestGetProcessCmdline(t *testing.T) {
+	res, err := KOS.GetProcessCmdline(os.Getpid())
+	if err != nil || len(res) != len(os.Args) || res[0] != os.Args[0] {
+		t.Error("GetProcessCmdline fail")
+		return
+	}
+	_, err = KOS.GetProcessCmdline(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessCmdline fail")
+		return
+	}
+}
+
+func TestGetProcessCwd(t *testing.T) {
+	dir, _ := os.Getwd()
+	res, err := KOS.GetProcessCwd(os.Getpid())
+	if err != nil || res != dir {
+		t.Error("GetProcessCwd fail")
+		return
+	}
+	_, err = KOS.GetProcessCwd(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessCwd fail")
+		return
+	}
+}
+
+func TestGetProcessEnviron(t *testing.T) {
+	res, err := KOS.GetProcessEnviron(os.Getpid())
+	if err != nil {
+		t.Error("GetProcessEnviron fail")
+		return
+	}
+	if path, ok := res["PATH"]; ok && path != os.Getenv("PATH") {
+		t.Error("GetProcessEnviron fail")
+		return
+	}
+	_, err = KOS.GetProcessEnviron(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessEnviron fail")
+		return
+	}
+}
+
+func TestGetProcessStartTime(t *testing.T) {
+	res, err := KOS.GetProcessStartTime(os.Getpid())
+	if err != nil {
+		t.Error("GetProcessStartTime fail")
+		return
+	}
+
+	// 启动时间应早于当前时间,且与Kuptime接近
+	diff := Kuptime.Sub(res)
+	if res.After(time.Now()) || diff < -time.Second || diff > time.Minute {
+		t.Error("GetProcessStartTime fail")
+		return
+	}
+
+	_, err = KOS.GetProcessStartTime(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessStartTime fail")
+		return
+	}
+}
+
+func TestGetProcessStateThreads(t *testing.T) {
+	pid := os.Getpid()
+	state, err := KOS.GetProcessState(pid)
+	if err != nil || !strings.Contains("RSD", state) {
+		t.Error("GetProcessState fail")
+		return
+	}
+
+	num, err := KOS.GetProcessThreads(pid)
+	if err != nil || num < 1 {
+		t.Error("GetProcessThreads fail")
+		return
+	}
+
+	_, err = KOS.GetProcessState(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessState fail")
+		return
+	}
+	_, err = KOS.GetProcessThreads(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessThreads fail")
+		return
+	}
+}
+
+func TestGetProcessMemory(t *testing.T) {
+	rss, vsz, err := KOS.GetProcessMemory(os.Getpid())
+	if err != nil || rss == 0 || vsz < rss {
+		t.Error("GetProcessMemory fail")
+		return
+	}
+	_, _, err = KOS.GetProcessMemory(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessMemory fail")
+		return
+	}
+}
+
+func TestGetProcessFdNum(t *testing.T) {
+	pid := os.Getpid()
+	num1, err := KOS.GetProcessFdNum(pid)
+	if err != nil || num1 < 3 {
+		t.Error("GetProcessFdNum fail")
+		return
+	}
+
+	f, _ := os.Open("./README.md")
+	num2, _ := KOS.GetProcessFdNum(pid)
+	_ = f.Close()
+	if num2 <= num1 {
+		t.Error("GetProcessFdNum fail")
+		return
+	}
+
+	_, err = KOS.GetProcessFdNum(INT_MAX)
+	if err == nil {
+		t.Error("GetProcessFdNum fail")
+		return
+	}
+}
+
+func TestGetProcessInfo(t *testing.T) {
+	pid := os.Getpid()
+	info, err := KOS.GetProcessInfo(pid)
+	if err != nil {
+		t.Error("GetProcessInfo fail")
+		return
+	}
+
+	exe, _ := os.Executable()
+	if info.Pid != pid || info.Ppid != os.Getppid() || info.Exe != exe || info.Name == "" || info.Rss == 0 || info.StartTime.IsZero() {
+		t.Error("GetProcessInfo fail")
+		return
+	}
+
+	_, err = KOS.GetProcessInfo(INT_MAX)
+	if !errors.Is(err, ErrProcessNotExist) {
+		t.Error("GetProcessInfo fail")
+		return
+	}
+}
+
+func BenchmarkGetProcessInfo(b *testing.B) {
+	b.ResetTimer()
+	pid := os.Getpid()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetProcessInfo(pid)
+	}
+}
+
+func TestGetProcesses(t *testing.T) {
+	res, err := KOS.GetProcesses()
+	if err != nil || len(res) == 0 {
+		t.Error("GetProcesses fail")
+		return
+	}
+
+	var found bool
+	pid := os.Getpid()
+	for _, item := range res {
+		if item.Pid == pid {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("GetProcesses fail")
+		return
+	}
+}
+
+func TestParseSocketAddr(t *testing.T) {
+	ip, port, err := parseSocketAddr("0100007F:1F90")
+	if err != nil || ip != "127.0.0.1" || port != 8080 {
+		t.Error("parseSocketAddr fail")
+		return
+	}
+
+	ip, port, err = parseSocketAddr("00000000000000000000000001000000:0050")
+	if err != nil || ip != "::1" || port != 80 {
+		t.Error("parseSocketAddr fail")
+		return
+	}
+
+	_, _, err = parseSocketAddr("0100007F")
+	if err == nil {
+		t.Error("parseSocketAddr fail")
+		return
+	}
+	_, _, err = parseSocketAddr("0100007F:XYZ")
+	if err == nil {
+		t.Error("parseSocketAddr fail")
+		return
+	}
+	_, _, err = parseSocketAddr("01007F:0050")
+	if err == nil {
+		t.Error("parseSocketAddr fail")
+		return
+	}
+}
+
+func TestGetSockets(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error("GetSockets fail")
+		return
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Error("GetSockets fail")
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	res, err := KOS.GetSockets("tcp")
+	if err != nil || len(res) == 0 {
+		t.Error("GetSockets fail")
+		return
+	}
+
+	exe, _ := os.Executable()
+	var found bool
+	for _, item := range res {
+		if item.LocalPort == port && item.State == "LISTEN" {
+			found = item.Pid == os.Getpid() && item.Exe == exe && item.LocalIP == "127.0.0.1"
+			break
+		}
+	}
+	if !found {
+		t.Error("GetSockets fail")
+		return
+	}
+
+	_, err = KOS.GetSockets("sctp")
+	if err == nil {
+		t.Error("GetSockets fail")
+		return
+	}
+
+	listens, err := KOS.GetListenSockets()
+	if err != nil || len(listens) == 0 {
+		t.Error("GetListenSockets fail")
+		return
+	}
+
+	established, err := KOS.GetEstablishedSockets()
+	if err != nil {
+		t.Error("GetEstablishedSockets fail")
+		return
+	}
+	found = false
+	localPort := conn.LocalAddr().(*net.TCPAddr).Port
+	for _, item := range established {
+		if item.LocalPort == localPort && item.RemotePort == port {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("GetEstablishedSockets fail")
+		return
+	}
+}
+
+func BenchmarkGetSockets(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetSockets()
+	}
+}
+
+func TestGetPidByPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error("GetPidByPort fail")
+		return
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Error("GetPidByPort fail")
+		return
+	}
+	defer func() {
+		_ = pc.Close()
+	}()
+
+	pid := os.Getpid()
+	res1 := KOS.GetPidByPort(ln.Addr().(*net.TCPAddr).Port)
+	res2 := KOS.GetPidByPort(pc.LocalAddr().(*net.UDPAddr).Port)
+	if res1 != pid || res2 != pid {
+		t.Error("GetPidByPort fail")
+		return
+	}
+
+	if KOS.GetPidByPort(0) != 0 {
+		t.Error("GetPidByPort fail")
+		return
+	}
+}
+
+func BenchmarkGetPidByPort(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		KOS.GetPidByPort(80)
+	}
+}
+
+func TestNewPidFile(t *testing.T) {
+	fpath := "./testdata/pid/test.pid"
+	_ = os.RemoveAll("./testdata/pid")
+
+	pf, err := KOS.NewPidFile(fpath)
+	if err != nil || pf.Pid != os.Getpid() {
+		t.Error("NewPidFile fail")
+		return
+	}
+
+	pid, err := readPidFromFile(fpath)
+	if err != nil || pid != os.Getpid() {
+		t.Error("NewPidFile fail")
+		return
+	}
+
+	// 文件已被加锁
+	pid, running, err := KOS.CheckPidFile(fpath)
+	if err != nil || !running || pid != os.Getpid() {
+		t.Error("CheckPidFile fail")
+		return
+	}
+
+	_, err = KOS.NewPidFile(fpath)
+	var perr *ProcessError
+	if !errors.Is(err, ErrPidFileLocked) || !errors.As(err, &perr) || perr.Pid != os.Getpid() {
+		t.Error("NewPidFile fail")
+		return
+	}
+
+	err = pf.Remove()
+	if err != nil || KFile.IsExist(fpath) {
+		t.Error("PidFile.Remove fail")
+		return
+	}
+	_ = pf.Remove()
+
+	_, _, err = KOS.CheckPidFile(fpath)
+	if err == nil {
+		t.Error("CheckPidFile fail")
+		return
+	}
+}
+
+func TestNewPidFileStale(t *testing.T) {
+	fpath := "./testdata/pid/stale.pid"
+
+	// 进程不存在
+	_ = KFile.WriteFile(fpath, []byte(fmt.Sprintf("%d\n", INT_MAX)), 0644)
+	pid, running, _ := KOS.CheckPidFile(fpath)
+	if pid != INT_MAX || running {
+		t.Error("CheckPidFile fail")
+		return
+	}
+
+	pf, err := KOS.NewPidFile(fpath)
+	if err != nil {
+		t.Error("NewPidFile fail")
+		return
+	}
+	pid, _ = readPidFromFile(fpath)
+	if pid != os.Getpid() {
+		t.Error("NewPidFile fail")
+		return
+	}
+	_ = pf.Remove()
+
+	// 进程存在,但为其他程序(父进程go test)
+	_ = KFile.WriteFile(fpath, []byte(fmt.Sprintf("%d\n", os.Getppid())), 0644)
+	_, running, _ = KOS.CheckPidFile(fpath)
+	if running {
+		t.Error("CheckPidFile fail")
+		return
+	}
+	pf, err = KOS.NewPidFile(fpath)
+	if err != nil {
+		t.Error("NewPidFile fail")
+		return
+	}
+	_ = pf.Remove()
+
+	// 非法内容
+	_ = KFile.WriteFile(fpath, []byte("hello"), 0644)
+	pf, err = KOS.NewPidFile(fpath)
+	if err != nil {
+		t.Error("NewPidFile fail")
+		return
+	}
+	_ = pf.Remove()
+
+	_, err = KOS.NewPidFile("/proc/kgo/test.pid")
+	if err == nil {
+		t.Error("NewPidFile fail")
+		return
+	}
+}
+
+func TestNewPidFileConcurrent(t *testing.T) {
+	fpath := "./testdata/pid/concurrent.pid"
+
+	for round := 0; round < 100; round++ {
+		// 多个竞争者同时回收过期的pid文件,只能有一个成功
+		_ = KFile.WriteFile(fpath, []byte(fmt.Sprintf("%d\n", INT_MAX)), 0644)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var owners []*PidFile
+		var locked int
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pf, err := KOS.NewPidFile(fpath)
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					owners = append(owners, pf)
+				} else if errors.Is(err, ErrPidFileLocked) {
+					locked++
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(owners) != 1 || locked != 15 {
+			t.Errorf("NewPidFile concurrent fail: %d owners, %d locked", len(owners), locked)
+			return
+		}
+		_, running, _ := KOS.CheckPidFile(fpath)
+		if !running || !isSameFile(owners[0].file, fpath) {
+			t.Error("NewPidFile concurrent fail")
+			return
+		}
+		_ = owners[0].Remove()
+	}
+}
+
+func BenchmarkNewPidFile(b *testing.B) {
+	b.ResetTimer()
+	fpath := "./testdata/pid/bench.pid"
+	for i := 0; i < b.N; i++ {
+		pf, err := KOS.NewPidFile(fpath)
+		if err == nil {
+			_ = pf.Remove()
+		}
+	}
+}
+
+func TestGoMemory(t *testing.T) {
+	if KOS.GoMemory() == 0 {
+		t.Error("GoMemory fail")
+		return
+	}
+}
+
+func BenchmarkGoMemory(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		KOS.GoMemory()
+	}
+}
+
+func TestCpuUsage(t *testing.T) {
+	res, err := KOS.CpuUsage(100 * time.Millisecond)
+	if err != nil || res.Percent < 0 || res.Percent > 100 || len(res.Cores) != runtime.NumCPU() {
+		t.Error("CpuUsage fail")
+		return
+	}
+
+	res, err = KOS.CpuUsage(0)
+	if err != nil || res.Percent <= 0 || res.Percent > 100 {
+		t.Error("CpuUsage fail")
+		return
+	}
+
+	if cpuPercent([2]uint64{10, 5}, [2]uint64{10, 5}) != 0 || cpuPercent([2]uint64{0, 0}, [2]uint64{200, 50}) != 75 {
+		t.Error("CpuUsage fail")
+		return
+	}
+
+	//空闲时间回退或超过总时间
+	if cpuPercent([2]uint64{100, 50}, [2]uint64{200, 40}) != 100 || cpuPercent([2]uint64{100, 50}, [2]uint64{110, 70}) != 0 {
+		t.Error("CpuUsage fail")
+		return
+	}
+}
+
+func BenchmarkCpuUsage(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.CpuUsage(0)
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	res, err := KOS.MemoryUsage()
+	if err != nil || res.Total == 0 || res.Available > res.Total || res.Used+res.Available != res.Total || res.UsedPercent > 100 {
+		t.Error("MemoryUsage fail")
+		return
+	}
+	if res.SwapUsed > res.SwapTotal {
+		t.Error("MemoryUsage fail")
+		return
+	}
+}
+
+func BenchmarkMemoryUsage(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.MemoryUsage()
+	}
+}
+
+func TestLoadAverage(t *testing.T) {
+	res, err := KOS.LoadAverage()
+	if err != nil || res.Load1 < 0 || res.Total < res.Running || res.Total == 0 {
+		t.Error("LoadAverage fail")
+		return
+	}
+}
+
+func BenchmarkLoadAverage(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.LoadAverage()
+	}
+}
+
+func TestUptime(t *testing.T) {
+	res, err := KOS.Uptime()
+	if err != nil || res <= 0 || res < KTime.ServiceUptime() {
+		t.Error("Uptime fail")
+		return
+	}
+}
+
+func BenchmarkUptime(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.Uptime()
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	if unescapeMountPath(`/mnt/my\040disk`) != "/mnt/my disk" || unescapeMountPath("/data") != "/data" || unescapeMountPath(`/a\b`) != `/a\b` {
+		t.Error("unescapeMountPath fail")
+		return
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	res, err := KOS.DiskUsage("./")
+	if err != nil || res.Total == 0 || res.Used > res.Total || res.Mountpoint == "" || res.Fstype == "" {
+		t.Error("DiskUsage fail")
+		return
+	}
+
+	_, err = KOS.DiskUsage("/nothing/kgo")
+	if err == nil {
+		t.Error("DiskUsage fail")
+		return
+	}
+
+	all, err := KOS.DiskUsages()
+	if err != nil || len(all) == 0 {
+		t.Error("DiskUsages fail")
+		return
+	}
+}
+
+func BenchmarkDiskUsage(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.DiskUsage("/")
+	}
+}
+
+func TestExec(t *testing.T) {
+	res, err := KOS.Exec("echo", []string{"hello", "world"})
+	if err != nil || string(res.Stdout) != "hello world\n" || res.ExitCode != 0 {
+		t.Error("Exec fail")
+		return
+	}
+
+	_, err = KOS.Exec("/nothing/kgo", nil)
+	var ee *ExecError
+	if err == nil || !errors.As(err, &ee) || ee.ExitCode != -1 {
+		t.Error("Exec fail")
+		return
+	}
+}
+
+func BenchmarkExec(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.Exec("true", nil)
+	}
+}
+
+func TestSystem(t *testing.T) {
+	res, err := KOS.System("echo out; echo err >&2; exit 3")
+	var ee *ExecError
+	if !errors.Is(err, ErrExecExitCode) || !errors.As(err, &ee) || ee.ExitCode != 3 || res.ExitCode != 3 {
+		t.Error("System fail")
+		return
+	} else if string(res.Stdout) != "out\n" || string(res.Stderr) != "err\n" {
+		t.Error("System fail")
+		return
+	}
+
+	//stdin,env,dir
+	dir := os.TempDir()
+	res, err = KOS.System("cat; echo $KGO_TEST; pwd", &ExecOptions{
+		Stdin: strings.NewReader("input\n"),
+		Env:   []string{"KGO_TEST=kgo"},
+		Dir:   dir,
+	})
+	if err != nil || !strings.HasPrefix(string(res.Stdout), "input\nkgo\n") || !strings.Contains(string(res.Stdout), dir) {
+		t.Error("System fail")
+		return
+	}
+
+	//按行回调
+	var outs, errs []string
+	_, err = KOS.System("printf 'a\\nb\\nc'; echo e1 >&2", &ExecOptions{
+		OnStdout: func(line string) { outs = append(outs, line) },
+		OnStderr: func(line string) { errs = append(errs, line) },
+	})
+	if err != nil || strings.Join(outs, ",") != "a,b,c" || strings.Join(errs, ",") != "e1" {
+		t.Error("System fail")
+		return
+	}
+}
+
+func TestSystemTimeout(t *testing.T) {
+	//子进程也应被终止,否则会等待其关闭输出
+	start := time.Now()
+	res, err := KOS.System("sleep 5 & sleep 5", &ExecOptions{Timeout: 200 * time.Millisecond})
+	if !errors.Is(err, ErrExecTimeout) || res.ExitCode != -1 || time.Since(start) > 3*time.Second {
+		t.Error("System timeout fail")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	_, err = KOS.System("sleep 5", &ExecOptions{Context: ctx})
+	if !errors.Is(err, ErrExecCanceled) || errors.Is(err, ErrExecTimeout) {
+		t.Error("System cancel fail")
+		return
+	}
+
+	//上下文到期视为超时
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = KOS.System("sleep 5", &ExecOptions{Context: ctx})
+	if !errors.Is(err, ErrExecTimeout) {
+		t.Error("System timeout fail")
+		return
+	}
+
+	//脱离进程组的子进程持有输出时,最多再等待execWaitDelay
+	start = time.Now()
+	res, err = KOS.System("setsid sleep 10 & echo ok", &ExecOptions{Timeout: 200 * time.Millisecond})
+	if err != nil || string(res.Stdout) != "ok\n" || time.Since(start) > execWaitDelay+3*time.Second {
+		t.Error("System timeout fail")
+		return
+	}
+
+	res, err = KOS.System("echo ok", &ExecOptions{Timeout: 5 * time.Second})
+	if err != nil || string(res.Stdout) != "ok\n" || res.Duration <= 0 {
+		t.Error("System timeout fail")
+		return
+	}
+}
+
+func BenchmarkSystem(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.System("true")
+	}
+}
+
+func TestGetNetInterfaces(t *testing.T) {
+	res, err := KOS.GetNetInterfaces()
+	if err != nil || len(res) == 0 {
+		t.Error("GetNetInterfaces fail")
+		return
+	}
+
+	var hasLo bool
+	for _, iface := range res {
+		if iface.Name == "lo" {
+			hasLo = KArr.InArray("loopback", iface.Flags) && KArr.InArray("127.0.0.1/8", iface.Addrs)
+		}
+	}
+	if !hasLo {
+		t.Error("GetNetInterfaces fail")
+		return
+	}
+}
+
+func BenchmarkGetNetInterfaces(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetNetInterfaces()
+	}
+}
+
+func TestGetOutboundIP(t *testing.T) {
+	//没有路由时(如离线环境)返回错误
+	ip, err := KOS.GetOutboundIPv4()
+	if err == nil && !KStr.IsIPv4(ip) {
+		t.Error("GetOutboundIPv4 fail")
+		return
+	}
+
+	ip, err = KOS.GetOutboundIPv6()
+	if err == nil && !KStr.IsIPv6(ip) {
+		t.Error("GetOutboundIPv6 fail")
+		return
+	}
+}
+
+func BenchmarkGetOutboundIPv4(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetOutboundIPv4()
+	}
+}
+
+func TestGetLocalIPs(t *testing.T) {
+	privates, publics, err := KOS.GetLocalIPs()
+	if err != nil {
+		t.Error("GetLocalIPs fail")
+		return
+	}
+
+	for _, ip := range privates {
+		if !KOS.IsPrivateIP(ip) {
+			t.Error("GetLocalIPs fail")
+			return
+		}
+	}
+	for _, ip := range publics {
+		if !KOS.IsPublicIP(ip) {
+			t.Error("GetLocalIPs fail")
+			return
+		}
+	}
+	if KArr.InArray("127.0.0.1", privates) || KArr.InArray("127.0.0.1", publics) {
+		t.Error("GetLocalIPs fail")
+		return
+	}
+}
+
+func BenchmarkGetLocalIPs(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = KOS.GetLocalIPs()
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		private  bool
+		public   bool
+		loopback bool
+		link     bool
+		multi    bool
+	}{
+		{"10.1.2.3", true, false, false, false, false},
+		{"172.16.0.1", true, false, false, false, false},
+		{"172.32.0.1", false, true, false, false, false},
+		{"192.168.1.1", true, false, false, false, false},
+		{"100.64.0.1", true, false, false, false, false},
+		{"8.8.8.8", false, true, false, false, false},
+		{"127.0.0.1", false, false, true, false, false},
+		{"169.254.1.1", false, false, false, true, false},
+		{"224.0.0.1", false, false, false, true, true},
+		{"239.1.1.1", false, false, false, false, true},
+		{"fd00::1", true, false, false, false, false},
+		{"2001:4860:4860::8888", false, true, false, false, false},
+		{"::1", false, false, true, false, false},
+		{"fe80::1", false, false, false, true, false},
+		{"ff02::1", false, false, false, true, true},
+		{"hello", false, false, false, false, false},
+	}
+	for _, test := range tests {
+		if KOS.IsPrivateIP(test.ip) != test.private || KOS.IsPublicIP(test.ip) != test.public ||
+			KOS.IsLoopbackIP(test.ip) != test.loopback || KOS.IsLinkLocalIP(test.ip) != test.link || KOS.IsMulticastIP(test.ip) != test.multi {
+			t.Errorf("IsPrivateIP fail: %s", test.ip)
+			return
+		}
+	}
+}
+
+func BenchmarkIsPrivateIP(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		KOS.IsPrivateIP("192.168.1.1")
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	gs := KOS.NewGracefulShutdown()
+
+	var reloads int32
+	gs.OnReload(func() {
+		atomic.AddInt32(&reloads, 1)
+	})
+	var order []string
+	gs.OnShutdown("first", func() {
+		order = append(order, "first")
+	}, 0)
+	gs.OnShutdown("slow", func() {
+		time.Sleep(time.Second)
+	}, 50*time.Millisecond)
+	gs.OnShutdown("panic", func() {
+		panic("hook panic")
+	}, time.Second)
+	gs.OnShutdown("last", func() {
+		order = append(order, "last")
+	}, time.Second)
+
+	_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	for i := 0; i < 100 && atomic.LoadInt32(&reloads) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&reloads) != 1 || gs.Context().Err() != nil {
+		t.Error("GracefulShutdown reload fail")
+		return
+	}
+
+	_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	err := gs.Wait()
+	if !errors.Is(err, ErrShutdownTimeout) || !strings.Contains(err.Error(), "slow") {
+		t.Error("GracefulShutdown fail")
+		return
+	} else if gs.Context().Err() == nil || strings.Join(order, ",") != "first,last" {
+		t.Error("GracefulShutdown fail")
+		return
+	}
+
+	//重复调用
+	if gs.Shutdown() != err {
+		t.Error("GracefulShutdown fail")
+		return
+	}
+}
+
+func TestGracefulShutdownForce(t *testing.T) {
+	gs := KOS.NewGracefulShutdown()
+	exitCode := make(chan int, 1)
+	gs.exit = func(code int) {
+		exitCode <- code
+	}
+
+	release := make(chan struct{})
+	gs.OnShutdown("block", func() {
+		<-release
+	}, 0)
+
+	_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	<-gs.Context().Done()
+	_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+
+	select {
+	case code := <-exitCode:
+		if code != 1 {
+			t.Error("GracefulShutdown force fail")
+			return
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("GracefulShutdown force fail")
+		return
+	}
+
+	close(release)
+	if gs.Wait() != nil {
+		t.Error("GracefulShutdown force fail")
+		return
+	}
+}
+
+func BenchmarkGracefulShutdown(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gs := KOS.NewGracefulShutdown()
+		gs.OnShutdown("noop", func() {}, time.Second)
+		_ = gs.Shutdown()
+	}
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	u, err := KOS.GetCurrentUser()
+	if err != nil || u.Uid != fmt.Sprint(KOS.Uid()) {
+		t.Error("GetCurrentUser fail")
+		return
+	}
+
+	if KOS.Euid() != os.Geteuid() || KOS.Gid() != os.Getgid() || KOS.Egid() != os.Getegid() || KOS.IsRoot() != (os.Geteuid() == 0) {
+		t.Error("GetCurrentUser fail")
+		return
+	}
+}
+
+func BenchmarkGetCurrentUser(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetCurrentUser()
+	}
+}
+
+func TestGetUidByName(t *testing.T) {
+	uid, err := KOS.GetUidByName("root")
+	if err != nil || uid != 0 {
+		t.Error("GetUidByName fail")
+		return
+	}
+
+	name, err := KOS.GetUserNameByUid(0)
+	if err != nil || name != "root" {
+		t.Error("GetUserNameByUid fail")
+		return
+	}
+
+	_, err = KOS.GetUidByName("kgo-nobody-user")
+	if err == nil {
+		t.Error("GetUidByName fail")
+		return
+	}
+
+	_, err = KOS.GetUserNameByUid(INT_MAX)
+	if err == nil {
+		t.Error("GetUserNameByUid fail")
+		return
+	}
+}
+
+func BenchmarkGetUidByName(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetUidByName("root")
+	}
+}
+
+func TestGetGidByName(t *testing.T) {
+	gid, err := KOS.GetGidByName("root")
+	if err != nil || gid != 0 {
+		t.Error("GetGidByName fail")
+		return
+	}
+
+	name, err := KOS.GetGroupNameByGid(0)
+	if err != nil || name != "root" {
+		t.Error("GetGroupNameByGid fail")
+		return
+	}
+
+	_, err = KOS.GetGidByName("kgo-nobody-group")
+	if err == nil {
+		t.Error("GetGidByName fail")
+		return
+	}
+
+	_, err = KOS.GetGroupNameByGid(INT_MAX)
+	if err == nil {
+		t.Error("GetGroupNameByGid fail")
+		return
+	}
+}
+
+func BenchmarkGetGidByName(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KOS.GetGidByName("root")
+	}
+}
@@ -0,0 +1,401 @@
+package kgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// packMaxRepeat 格式码允许的最大重复次数,防止畸形格式导致分配过多内存
+const packMaxRepeat = 1 << 24
+
+// packNumeric 获取数值格式码的字节数、字节序及类型(i有符号,u无符号,f浮点);非数值格式码返回size为0.
+// 字节序为nil时使用本机字节序.
+func packNumeric(code byte) (size int, order binary.ByteOrder, kind byte) {
+	switch code {
+	case 'c':
+		return 1, binary.BigEndian, 'i'
+	case 'C':
+		return 1, binary.BigEndian, 'u'
+	case 's':
+		return 2, nil, 'i'
+	case 'S':
+		return 2, nil, 'u'
+	case 'n':
+		return 2, binary.BigEndian, 'u'
+	case 'v':
+		return 2, binary.LittleEndian, 'u'
+	case 'N':
+		return 4, binary.BigEndian, 'u'
+	case 'V':
+		return 4, binary.LittleEndian, 'u'
+	case 'q':
+		return 8, nil, 'i'
+	case 'Q':
+		return 8, nil, 'u'
+	case 'J':
+		return 8, binary.BigEndian, 'u'
+	case 'P':
+		return 8, binary.LittleEndian, 'u'
+	case 'f':
+		return 4, nil, 'f'
+	case 'g':
+		return 4, binary.LittleEndian, 'f'
+	case 'G':
+		return 4, binary.BigEndian, 'f'
+	case 'd':
+		return 8, nil, 'f'
+	case 'e':
+		return 8, binary.LittleEndian, 'f'
+	case 'E':
+		return 8, binary.BigEndian, 'f'
+	}
+	return 0, nil, 0
+}
+
+// packInt 将参数转换为整数;字符串按数字解析,同php.
+func packInt(arg interface{}) (uint64, error) {
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return uint64(int64(v.Float())), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.String:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return uint64(i), nil
+		} else if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u, nil
+		}
+	}
+	return 0, fmt.Errorf("[Pack] cannot pack %T(%v) as integer", arg, arg)
+}
+
+// packFloat 将参数转换为浮点数.
+func packFloat(arg interface{}) (float64, error) {
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("[Pack] cannot pack %T(%v) as float", arg, arg)
+}
+
+// parsePackRepeat 解析格式码后的重复次数;*返回-1,没有时返回1;次数无效或超过packMaxRepeat时返回错误.
+func parsePackRepeat(format string, pos int) (count int, next int, err error) {
+	if pos < len(format) && format[pos] == '*' {
+		return -1, pos + 1, nil
+	}
+
+	next = pos
+	for next < len(format) && format[next] >= '0' && format[next] <= '9' {
+		next++
+	}
+	if next == pos {
+		return 1, next, nil
+	}
+	count, err = strconv.Atoi(format[pos:next])
+	if err != nil || count > packMaxRepeat {
+		return 0, next, fmt.Errorf("repeat count %s out of range", format[pos:next])
+	}
+	return
+}
+
+// Pack 将数据打包成二进制字符串,同php的pack.format由格式码及可选的重复次数(数字或*)组成,支持:
+// a(NUL填充的字符串)、A(空格填充的字符串)、h/H(低/高半字节在前的十六进制字符串)、
+// c/C(有/无符号字节)、s/S(本机序16位)、n/v(大/小端16位)、N/V(大/小端32位)、q/Q(本机序64位)、J/P(大/小端64位)、
+// f/g/G(本机序/小端/大端单精度浮点)、d/e/E(本机序/小端/大端双精度浮点)、x(NUL字节)、X(回退一个字节)、@(NUL填充到绝对位置).
+func (kc *LkkConvert) Pack(format string, args ...interface{}) ([]byte, error) {
+	var buf []byte
+	argIdx := 0
+	nextArg := func(code byte) (interface{}, error) {
+		if argIdx >= len(args) {
+			return nil, fmt.Errorf("[Pack] type %c: not enough arguments", code)
+		}
+		argIdx++
+		return args[argIdx-1], nil
+	}
+
+	for i := 0; i < len(format); {
+		code := format[i]
+		count, next, err := parsePackRepeat(format, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("[Pack] type %c: %s", code, err.Error())
+		}
+		i = next
+
+		switch code {
+		case 'a', 'A', 'h', 'H':
+			arg, err := nextArg(code)
+			if err != nil {
+				return nil, err
+			}
+			str := KConv.ToStr(arg)
+			if count < 0 {
+				count = len(str)
+			}
+
+			if code == 'a' || code == 'A' {
+				pad := byte(0)
+				if code == 'A' {
+					pad = ' '
+				}
+				if len(str) >= count {
+					buf = append(buf, str[:count]...)
+				} else {
+					buf = append(buf, str...)
+					buf = append(buf, bytes.Repeat([]byte{pad}, count-len(str))...)
+				}
+				continue
+			}
+
+			if count > len(str) {
+				return nil, fmt.Errorf("[Pack] type %c: not enough characters in string", code)
+			}
+			str = str[:count]
+			if len(str)%2 == 1 {
+				str += "0"
+			}
+			if code == 'h' {
+				// 低半字节在前,交换每个字节的两个字符
+				swapped := []byte(str)
+				for j := 0; j < len(swapped); j += 2 {
+					swapped[j], swapped[j+1] = swapped[j+1], swapped[j]
+				}
+				str = string(swapped)
+			}
+			decoded, err := hex.DecodeString(str)
+			if err != nil {
+				return nil, fmt.Errorf("[Pack] type %c: illegal hex digit", code)
+			}
+			buf = append(buf, decoded...)
+		case 'x':
+			if count > 0 {
+				buf = append(buf, make([]byte, count)...)
+			}
+		case 'X':
+			if count < 0 {
+				count = 0
+			}
+			if count > len(buf) {
+				return nil, fmt.Errorf("[Pack] type X: outside of string")
+			}
+			buf = buf[:len(buf)-count]
+		case '@':
+			if count < 0 {
+				count = len(buf)
+			}
+			if count > len(buf) {
+				buf = append(buf, make([]byte, count-len(buf))...)
+			} else {
+				buf = buf[:count]
+			}
+		default:
+			size, order, kind := packNumeric(code)
+			if size == 0 {
+				return nil, fmt.Errorf("[Pack] type %c: unknown format code", code)
+			}
+			if order == nil {
+				order = getEndian()
+			}
+			if count < 0 {
+				count = len(args) - argIdx
+			}
+
+			for j := 0; j < count; j++ {
+				arg, err := nextArg(code)
+				if err != nil {
+					return nil, err
+				}
+
+				var bits uint64
+				if kind == 'f' {
+					f, err := packFloat(arg)
+					if err != nil {
+						return nil, err
+					}
+					if size == 4 {
+						bits = uint64(math.Float32bits(float32(f)))
+					} else {
+						bits = math.Float64bits(f)
+					}
+				} else if bits, err = packInt(arg); err != nil {
+					return nil, err
+				}
+
+				b := make([]byte, size)
+				switch size {
+				case 1:
+					b[0] = byte(bits)
+				case 2:
+					order.PutUint16(b, uint16(bits))
+				case 4:
+					order.PutUint32(b, uint32(bits))
+				case 8:
+					order.PutUint64(b, bits)
+				}
+				buf = append(buf, b...)
+			}
+		}
+	}
+
+	if argIdx < len(args) {
+		return nil, fmt.Errorf("[Pack] %d arguments unused", len(args)-argIdx)
+	}
+	return buf, nil
+}
+
+// Unpack 从二进制字符串中解包数据,同php的unpack.format由"/"分隔的多个"格式码[重复次数][名称]"组成,格式码同Pack.
+// 重复次数大于1或为*时,键名为名称加序号(从1开始);没有名称时键名为序号.
+// c/C/s/S/n/v/N/V的值为int,q为int64,Q/J/P为uint64,浮点为float64,a/A/h/H为string(A会去除末尾的空白和NUL).
+func (kc *LkkConvert) Unpack(format string, data []byte) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+	pos := 0
+	for _, part := range strings.Split(format, "/") {
+		if part == "" {
+			continue
+		}
+
+		code := part[0]
+		count, next, err := parsePackRepeat(part, 1)
+		if err != nil {
+			return nil, fmt.Errorf("[Unpack] type %c: %s", code, err.Error())
+		}
+		name := part[next:]
+		repeated := count != 1
+
+		switch code {
+		case 'a', 'A', 'h', 'H':
+			size := count
+			if code == 'h' || code == 'H' {
+				size = (count + 1) / 2
+			}
+			if count < 0 {
+				size = len(data) - pos
+			}
+			if pos+size > len(data) {
+				return nil, fmt.Errorf("[Unpack] type %c: not enough input, need %d, have %d", code, size, len(data)-pos)
+			}
+
+			raw := data[pos : pos+size]
+			pos += size
+			if name == "" {
+				name = "1"
+			}
+			switch code {
+			case 'a':
+				res[name] = string(raw)
+			case 'A':
+				res[name] = strings.TrimRight(string(raw), " \t\r\n\x00")
+			default:
+				str := hex.EncodeToString(raw)
+				if code == 'h' {
+					swapped := []byte(str)
+					for j := 0; j < len(swapped); j += 2 {
+						swapped[j], swapped[j+1] = swapped[j+1], swapped[j]
+					}
+					str = string(swapped)
+				}
+				if count >= 0 {
+					str = str[:count]
+				}
+				res[name] = str
+			}
+		case 'x':
+			if count < 0 {
+				count = 0
+			}
+			if pos+count > len(data) {
+				return nil, fmt.Errorf("[Unpack] type x: outside of string")
+			}
+			pos += count
+		case 'X':
+			if count < 0 {
+				count = 0
+			}
+			if count > pos {
+				return nil, fmt.Errorf("[Unpack] type X: outside of string")
+			}
+			pos -= count
+		case '@':
+			if count < 0 {
+				count = pos
+			}
+			if count > len(data) {
+				return nil, fmt.Errorf("[Unpack] type @: outside of string")
+			}
+			pos = count
+		default:
+			size, order, kind := packNumeric(code)
+			if size == 0 {
+				return nil, fmt.Errorf("[Unpack] type %c: unknown format code", code)
+			}
+			if order == nil {
+				order = getEndian()
+			}
+			if count < 0 {
+				count = (len(data) - pos) / size
+			}
+
+			for j := 0; j < count; j++ {
+				if pos+size > len(data) {
+					return nil, fmt.Errorf("[Unpack] type %c: not enough input, need %d, have %d", code, size, len(data)-pos)
+				}
+				b := data[pos : pos+size]
+				pos += size
+
+				var val interface{}
+				switch {
+				case kind == 'f' && size == 4:
+					val = float64(math.Float32frombits(order.Uint32(b)))
+				case kind == 'f':
+					val = math.Float64frombits(order.Uint64(b))
+				case size == 1 && kind == 'i':
+					val = int(int8(b[0]))
+				case size == 1:
+					val = int(b[0])
+				case size == 2 && kind == 'i':
+					val = int(int16(order.Uint16(b)))
+				case size == 2:
+					val = int(order.Uint16(b))
+				case size == 4:
+					val = int(order.Uint32(b))
+				case kind == 'i':
+					val = int64(order.Uint64(b))
+				default:
+					val = order.Uint64(b)
+				}
+
+				key := name
+				if repeated {
+					key = name + strconv.Itoa(j+1)
+				} else if key == "" {
+					key = "1"
+				}
+				res[key] = val
+			}
+		}
+	}
+
+	return res, nil
+}
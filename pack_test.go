@@ -0,0 +1,133 @@
+package kgo
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		format   string
+		args     []interface{}
+		expected string
+	}{
+		{"nvc*", []interface{}{0x1234, 0x5678, 65, 66}, "1234785641" + "42"},
+		{"NV", []interface{}{1, 1}, "0000000101000000"},
+		{"C3", []interface{}{255, "1", true}, "ff0101"},
+		{"c", []interface{}{-1}, "ff"},
+		{"JP", []interface{}{uint64(1), -2}, "0000000000000001" + "feffffffffffffff"},
+		{"eE", []interface{}{1.5, 1.5}, "000000000000f83f" + "3ff8000000000000"},
+		{"gG", []interface{}{1.5, "1.5"}, "0000c03f" + "3fc00000"},
+		{"a5A5a*", []interface{}{"ab", "cd", "ef"}, "6162000000" + "6364202020" + "6566"},
+		{"a1", []interface{}{"hello"}, "68"},
+		{"H*h*H3", []interface{}{"1aF", "1a", "abcd"}, "1af0" + "a1" + "abc0"},
+		{"x2X@4", []interface{}{}, "00000000"},
+		{"C@1", []interface{}{1}, "01"},
+		{"a4X2a1", []interface{}{"abcd", "z"}, "61627a"},
+	}
+	for _, test := range tests {
+		res, err := KConv.Pack(test.format, test.args...)
+		if err != nil || hex.EncodeToString(res) != test.expected {
+			t.Errorf("Pack(%q) expected %s, got %x, err %v", test.format, test.expected, res, err)
+			return
+		}
+	}
+
+	//本机字节序
+	res, _ := KConv.Pack("S", 1)
+	if (getEndian().Uint16(res)) != 1 {
+		t.Error("Pack fail")
+		return
+	}
+
+	errs := []struct {
+		format string
+		args   []interface{}
+	}{
+		{"N", nil},
+		{"N", []interface{}{1, 2}},
+		{"Y", []interface{}{1}},
+		{"N", []interface{}{"abc"}},
+		{"d", []interface{}{[]int{1}}},
+		{"H3", []interface{}{"ab"}},
+		{"H*", []interface{}{"zz"}},
+		{"X", nil},
+		//重复次数过大
+		{"x9999999999999999999", nil},
+		{"@9999999999999999999", nil},
+		{"x16777217", nil},
+		{"a99999999", []interface{}{"a"}},
+	}
+	for _, test := range errs {
+		if _, err := KConv.Pack(test.format, test.args...); err == nil {
+			t.Errorf("Pack(%q) should fail", test.format)
+			return
+		}
+	}
+}
+
+func BenchmarkPack(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KConv.Pack("nNa4", 1, 2, "abcd")
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	data, _ := hex.DecodeString("1234" + "78563412" + "ff" + "fe" + "6869200000" + "a1" + "000000000000f83f" + "0000000000000002")
+	res, err := KConv.Unpack("nlen/Vid/Cbyte/csigned/A5name/h2hex/edouble/Jbig", data)
+	if err != nil {
+		t.Error("Unpack fail")
+		return
+	}
+	if res["len"] != 0x1234 || res["id"] != 0x12345678 || res["byte"] != 255 || res["signed"] != -2 {
+		t.Error("Unpack fail")
+		return
+	} else if res["name"] != "hi" || res["hex"] != "1a" || res["double"] != 1.5 || res["big"] != uint64(2) {
+		t.Error("Unpack fail")
+		return
+	}
+
+	//重复次数和无名称
+	res, err = KConv.Unpack("C2c/n*", []byte{1, 2, 0, 3, 0, 4})
+	if err != nil || len(res) != 4 || res["c1"] != 1 || res["c2"] != 2 || res["1"] != 3 || res["2"] != 4 {
+		t.Error("Unpack fail")
+		return
+	}
+	res, err = KConv.Unpack("N", []byte{0, 0, 0, 9})
+	if err != nil || res["1"] != 9 {
+		t.Error("Unpack fail")
+		return
+	}
+
+	//定位
+	res, err = KConv.Unpack("x2/Ca/X1/Cb/@0/a*rest", []byte("abcd"))
+	if err != nil || res["a"] != int('c') || res["b"] != int('c') || res["rest"] != "abcd" {
+		t.Error("Unpack fail")
+		return
+	}
+
+	//往返
+	packed, _ := KConv.Pack("qQsSfdH*", -5, uint64(1<<63), -3, 65535, 0.5, -2.25, "abc")
+	res, err = KConv.Unpack("qq/QQ/ss/SS/ff/dd/H*hex", packed)
+	if err != nil || res["q"] != int64(-5) || res["Q"] != uint64(1<<63) || res["s"] != -3 || res["S"] != 65535 ||
+		res["f"] != 0.5 || res["d"] != -2.25 || res["hex"] != "abc0" {
+		t.Error("Unpack fail")
+		return
+	}
+
+	for _, format := range []string{"N", "a5", "Y", "x5", "X1", "@9", "a9999999999999999999", "x99999999"} {
+		if _, err = KConv.Unpack(format, []byte{1, 2}); err == nil {
+			t.Errorf("Unpack(%q) should fail", format)
+			return
+		}
+	}
+}
+
+func BenchmarkUnpack(b *testing.B) {
+	data := []byte{0, 1, 0, 0, 0, 2, 'a', 'b', 'c', 'd'}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KConv.Unpack("nlen/Nid/a4name", data)
+	}
+}
@@ -0,0 +1,642 @@
+package kgo
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// phpMaxDepth 反序列化时允许的最大嵌套层数
+const phpMaxDepth = 1000
+
+// phpFloat 按php的格式输出浮点数,如1.5、1.0E+25、INF.
+func phpFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case math.IsNaN(f):
+		return "NAN"
+	}
+
+	str := strconv.FormatFloat(f, 'g', -1, 64)
+	if pos := strings.IndexByte(str, 'e'); pos >= 0 {
+		mant, exp := str[:pos], str[pos+1:]
+		if !strings.Contains(mant, ".") {
+			mant += ".0"
+		}
+		str = mant + "E" + exp[:1] + strings.TrimLeft(exp[1:], "0")
+	}
+	return str
+}
+
+// phpWriteString 写入php字符串,长度为字节数.
+func phpWriteString(buf *bytes.Buffer, str string) {
+	buf.WriteString("s:")
+	buf.WriteString(strconv.Itoa(len(str)))
+	buf.WriteString(`:"`)
+	buf.WriteString(str)
+	buf.WriteString(`";`)
+}
+
+// phpWriteKey 写入数组的键;同php,整数形式的字符串键写为整数.
+func phpWriteKey(buf *bytes.Buffer, key reflect.Value) {
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString("i:" + strconv.FormatInt(key.Int(), 10) + ";")
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString("i:" + strconv.FormatUint(key.Uint(), 10) + ";")
+		return
+	}
+
+	str := KConv.ToStr(key.Interface())
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil && strconv.FormatInt(i, 10) == str {
+		buf.WriteString("i:" + str + ";")
+	} else {
+		phpWriteString(buf, str)
+	}
+}
+
+// phpStructFields 获取结构体可序列化的字段及其(已处理可见性的)属性名.
+// 标签形如`php:"name,protected"`或`php:",private"`,"-"表示忽略;匿名结构体字段会被展开.
+func phpStructFields(t reflect.Type) []phpField {
+	var res []phpField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("php")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, sub := range phpStructFields(ft) {
+					res = append(res, phpField{index: append([]int{i}, sub.index...), name: sub.name})
+				}
+				continue
+			} else if field.PkgPath != "" {
+				continue
+			}
+		}
+
+		name, visibility := field.Name, ""
+		if tag != "" {
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) == 2 {
+				visibility = parts[1]
+			}
+		}
+		switch visibility {
+		case "protected":
+			name = "\x00*\x00" + name
+		case "private":
+			name = "\x00" + t.Name() + "\x00" + name
+		}
+		res = append(res, phpField{index: []int{i}, name: name})
+	}
+
+	return res
+}
+
+// phpEncode 将变量编码为php序列化格式.
+func phpEncode(buf *bytes.Buffer, v reflect.Value) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			buf.WriteString("N;")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		buf.WriteString("N;")
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteString("b:1;")
+		} else {
+			buf.WriteString("b:0;")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString("i:" + strconv.FormatInt(v.Int(), 10) + ";")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString("i:" + strconv.FormatUint(v.Uint(), 10) + ";")
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString("d:" + phpFloat(v.Float()) + ";")
+	case reflect.String:
+		phpWriteString(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			phpWriteString(buf, KConv.ToStr(v.Interface()))
+			return nil
+		}
+
+		buf.WriteString("a:" + strconv.Itoa(v.Len()) + ":{")
+		for i := 0; i < v.Len(); i++ {
+			buf.WriteString("i:" + strconv.Itoa(i) + ";")
+			if err := phpEncode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case reflect.Map:
+		// 按键排序,保证输出稳定
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			switch keys[i].Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return keys[i].Int() < keys[j].Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return keys[i].Uint() < keys[j].Uint()
+			}
+			return KConv.ToStr(keys[i].Interface()) < KConv.ToStr(keys[j].Interface())
+		})
+
+		buf.WriteString("a:" + strconv.Itoa(len(keys)) + ":{")
+		for _, key := range keys {
+			phpWriteKey(buf, key)
+			if err := phpEncode(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case reflect.Struct:
+		fields := phpStructFields(v.Type())
+		name := v.Type().Name()
+		buf.WriteString("O:" + strconv.Itoa(len(name)) + `:"` + name + `":` + strconv.Itoa(len(fields)) + ":{")
+		for _, field := range fields {
+			phpWriteString(buf, field.name)
+			fv := v
+			for _, idx := range field.index {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						break
+					}
+					fv = fv.Elem()
+				}
+				fv = fv.Field(idx)
+			}
+			// 匿名结构体指针为nil时,fv停留在该指针上,编码为N
+			if err := phpEncode(buf, fv); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	default:
+		return fmt.Errorf("[Serialize] unsupported type: %s", v.Type())
+	}
+
+	return nil
+}
+
+// Serialize 将变量序列化为php的serialize格式.
+// 切片为索引数组,字典为关联数组(按键排序),结构体为以类型名为类名的对象;
+// 结构体字段可用`php:"name,protected"`或`php:"name,private"`标签指定属性名和可见性.
+func (ks *LkkString) Serialize(val interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := phpEncode(&buf, reflect.ValueOf(val)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// errorf 生成带位置的解析错误.
+func (d *phpDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("[Unserialize] offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+// expect 检查并跳过指定内容.
+func (d *phpDecoder) expect(str string) error {
+	if !bytes.HasPrefix(d.data[d.pos:], []byte(str)) {
+		return d.errorf("expected %q", str)
+	}
+	d.pos += len(str)
+	return nil
+}
+
+// readUntil 读取到分隔符为止,并跳过分隔符.
+func (d *phpDecoder) readUntil(delim byte) (string, error) {
+	pos := bytes.IndexByte(d.data[d.pos:], delim)
+	if pos < 0 {
+		return "", d.errorf("expected %q", delim)
+	}
+	res := string(d.data[d.pos : d.pos+pos])
+	d.pos += pos + 1
+	return res, nil
+}
+
+// readLength 读取长度或个数.
+func (d *phpDecoder) readLength() (int, error) {
+	str, err := d.readUntil(':')
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil || n < 0 {
+		return 0, d.errorf("invalid length %q", str)
+	}
+	return n, nil
+}
+
+// readBytes 读取n个字节,并检查其前后的引号.
+func (d *phpDecoder) readBytes(n int) (string, error) {
+	if err := d.expect(`"`); err != nil {
+		return "", err
+	} else if n > len(d.data)-d.pos {
+		return "", d.errorf("unexpected end of data")
+	}
+	res := string(d.data[d.pos : d.pos+n])
+	d.pos += n
+	return res, d.expect(`"`)
+}
+
+// decodeMembers 解析数组或对象的n个成员.
+func (d *phpDecoder) decodeMembers(n int) (*phpArray, error) {
+	// 预分配不超过剩余数据长度,防止恶意的个数
+	size := n
+	if rest := len(d.data) - d.pos; size > rest {
+		size = rest
+	}
+	arr := &phpArray{keys: make([]interface{}, 0, size), vals: make([]interface{}, 0, size)}
+
+	if err := d.expect("{"); err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case int64, string:
+		default:
+			return nil, d.errorf("invalid array key type %T", key)
+		}
+
+		val, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		arr.keys = append(arr.keys, key)
+		arr.vals = append(arr.vals, val)
+	}
+
+	return arr, d.expect("}")
+}
+
+// decode 解析一个值.
+func (d *phpDecoder) decode() (interface{}, error) {
+	if d.pos+2 > len(d.data) {
+		return nil, d.errorf("unexpected end of data")
+	}
+
+	d.depth++
+	defer func() {
+		d.depth--
+	}()
+	if d.depth > phpMaxDepth {
+		return nil, d.errorf("exceeded max depth %d", phpMaxDepth)
+	}
+
+	typ := d.data[d.pos]
+	if typ == 'N' {
+		return nil, d.expect("N;")
+	} else if err := d.expect(string(typ) + ":"); err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case 'b':
+		str, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		} else if str != "0" && str != "1" {
+			return nil, d.errorf("invalid bool %q", str)
+		}
+		return str == "1", nil
+	case 'i':
+		str, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, d.errorf("invalid int %q", str)
+		}
+		return i, nil
+	case 'd':
+		str, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		switch str {
+		case "INF":
+			return math.Inf(1), nil
+		case "-INF":
+			return math.Inf(-1), nil
+		case "NAN":
+			return math.NaN(), nil
+		}
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, d.errorf("invalid float %q", str)
+		}
+		return f, nil
+	case 's':
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		str, err := d.readBytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return str, d.expect(";")
+	case 'a':
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMembers(n)
+	case 'O':
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		class, err := d.readBytes(n)
+		if err != nil {
+			return nil, err
+		} else if err = d.expect(":"); err != nil {
+			return nil, err
+		}
+		if n, err = d.readLength(); err != nil {
+			return nil, err
+		}
+		arr, err := d.decodeMembers(n)
+		if err != nil {
+			return nil, err
+		}
+		return &phpObject{class: class, phpArray: *arr}, nil
+	}
+
+	return nil, d.errorf("unsupported type %q", typ)
+}
+
+// phpPropertyName 去除属性名中表示可见性的前缀.
+func phpPropertyName(name string) string {
+	if strings.HasPrefix(name, "\x00") {
+		if pos := strings.IndexByte(name[1:], 0); pos >= 0 {
+			return name[pos+2:]
+		}
+	}
+	return name
+}
+
+// phpNative 将解析结果转换为Go的通用类型.
+// 整数为int,连续从0开始的数组为[]interface{},其他数组和对象为map[string]interface{}.
+func phpNative(raw interface{}) interface{} {
+	switch val := raw.(type) {
+	case int64:
+		return int(val)
+	case *phpArray:
+		isList := true
+		for i, key := range val.keys {
+			if k, ok := key.(int64); !ok || k != int64(i) {
+				isList = false
+				break
+			}
+		}
+		if isList {
+			res := make([]interface{}, len(val.vals))
+			for i, v := range val.vals {
+				res[i] = phpNative(v)
+			}
+			return res
+		}
+
+		res := make(map[string]interface{}, len(val.keys))
+		for i, key := range val.keys {
+			res[KConv.ToStr(key)] = phpNative(val.vals[i])
+		}
+		return res
+	case *phpObject:
+		res := make(map[string]interface{}, len(val.keys))
+		for i, key := range val.keys {
+			res[phpPropertyName(KConv.ToStr(key))] = phpNative(val.vals[i])
+		}
+		return res
+	}
+	return raw
+}
+
+// phpAssign 将解析结果赋值给v.
+func phpAssign(v reflect.Value, raw interface{}) error {
+	if raw == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return phpAssign(v.Elem(), raw)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(phpNative(raw)))
+			return nil
+		}
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("[Unserialize] cannot unserialize %T into %s", raw, v.Type())
+	}
+
+	switch val := raw.(type) {
+	case bool:
+		if v.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		v.SetBool(val)
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(val) {
+				return fmt.Errorf("[Unserialize] %d overflows %s", val, v.Type())
+			}
+			v.SetInt(val)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if val < 0 || v.OverflowUint(uint64(val)) {
+				return fmt.Errorf("[Unserialize] %d overflows %s", val, v.Type())
+			}
+			v.SetUint(uint64(val))
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(val))
+		case reflect.Bool:
+			v.SetBool(val != 0)
+		case reflect.String:
+			v.SetString(strconv.FormatInt(val, 10))
+		default:
+			return mismatch()
+		}
+	case float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(val)
+		case reflect.String:
+			v.SetString(phpFloat(val))
+		default:
+			return mismatch()
+		}
+	case string:
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(val)
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				return mismatch()
+			}
+			v.SetBytes([]byte(val))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || v.OverflowInt(i) {
+				return mismatch()
+			}
+			v.SetInt(i)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return mismatch()
+			}
+			v.SetFloat(f)
+		default:
+			return mismatch()
+		}
+	case *phpArray:
+		return phpAssignArray(v, val, false)
+	case *phpObject:
+		return phpAssignArray(v, &val.phpArray, true)
+	}
+
+	return nil
+}
+
+// phpAssignArray 将数组或对象赋值给字典、切片、数组或结构体.
+func phpAssignArray(v reflect.Value, arr *phpArray, isObject bool) error {
+	keyName := func(key interface{}) string {
+		name := KConv.ToStr(key)
+		if isObject {
+			name = phpPropertyName(name)
+		}
+		return name
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(arr.keys)))
+		}
+		kt, et := v.Type().Key(), v.Type().Elem()
+		for i, key := range arr.keys {
+			kv := reflect.New(kt).Elem()
+			if kt.Kind() == reflect.String {
+				kv.SetString(keyName(key))
+			} else if err := phpAssign(kv, key); err != nil {
+				return err
+			}
+
+			ev := reflect.New(et).Elem()
+			if err := phpAssign(ev, arr.vals[i]); err != nil {
+				return err
+			}
+			v.SetMapIndex(kv, ev)
+		}
+	case reflect.Slice:
+		res := reflect.MakeSlice(v.Type(), len(arr.vals), len(arr.vals))
+		for i, val := range arr.vals {
+			if err := phpAssign(res.Index(i), val); err != nil {
+				return err
+			}
+		}
+		v.Set(res)
+	case reflect.Array:
+		for i := 0; i < v.Len() && i < len(arr.vals); i++ {
+			if err := phpAssign(v.Index(i), arr.vals[i]); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := phpStructFields(v.Type())
+		for i, key := range arr.keys {
+			name := keyName(key)
+			var found *phpField
+			for j := range fields {
+				if phpPropertyName(fields[j].name) == name {
+					found = &fields[j]
+					break
+				} else if found == nil && strings.EqualFold(phpPropertyName(fields[j].name), name) {
+					found = &fields[j]
+				}
+			}
+			if found == nil {
+				continue
+			}
+
+			fv := v
+			for _, idx := range found.index {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						//同encoding/json,无法为未导出的匿名结构体指针分配值
+						if !fv.CanSet() {
+							return fmt.Errorf("[Unserialize] cannot set embedded pointer to unexported struct %s", fv.Type().Elem())
+						}
+						fv.Set(reflect.New(fv.Type().Elem()))
+					}
+					fv = fv.Elem()
+				}
+				fv = fv.Field(idx)
+			}
+			if err := phpAssign(fv, arr.vals[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("[Unserialize] cannot unserialize array into %s", v.Type())
+	}
+
+	return nil
+}
+
+// Unserialize 解析php的serialize格式数据到val,注意val使用指针.
+// val可以是interface{}(数组为[]interface{}或map[string]interface{},对象为map[string]interface{})、字典、切片或结构体;
+// 对象属性的可见性前缀会被去除,结构体字段按标签或字段名匹配(不区分大小写).不支持引用(r/R)和自定义序列化(C).
+func (ks *LkkString) Unserialize(data []byte, val interface{}) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("[Unserialize] val must be a non-nil pointer")
+	}
+
+	d := &phpDecoder{data: data}
+	raw, err := d.decode()
+	if err != nil {
+		return err
+	} else if d.pos != len(data) {
+		return d.errorf("unexpected data after value")
+	}
+
+	return phpAssign(rv.Elem(), raw)
+}
@@ -0,0 +1,207 @@
+package kgo
+
+import (
+	"math"
+	"testing"
+)
+
+// phpUser 对应php中的User类
+type phpUser struct {
+	Name    string `php:"name"`
+	Age     int    `php:"age,protected"`
+	Secret  string `php:"secret,private"`
+	Ignored string `php:"-"`
+	private int
+}
+
+// phpAdmin 包含匿名结构体
+type phpAdmin struct {
+	phpUser
+	Level int `php:"level"`
+}
+
+// phpInner 未导出的结构体
+type phpInner struct {
+	X int
+}
+
+// phpOuter 包含未导出结构体的匿名指针
+type phpOuter struct {
+	*phpInner
+	Y int
+}
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		param    interface{}
+		expected string
+	}{
+		{nil, "N;"},
+		{true, "b:1;"},
+		{false, "b:0;"},
+		{-12, "i:-12;"},
+		{uint8(200), "i:200;"},
+		{1.5, "d:1.5;"},
+		{float32(0.25), "d:0.25;"},
+		{1e25, "d:1.0E+25;"},
+		{1.5e-7, "d:1.5E-7;"},
+		{math.Inf(-1), "d:-INF;"},
+		{"中文", `s:6:"中文";`},
+		{[]byte("hi"), `s:2:"hi";`},
+		{[]int{1, 2}, "a:2:{i:0;i:1;i:1;i:2;}"},
+		{[]string{}, "a:0:{}"},
+		{map[string]interface{}{"b": nil, "a": []interface{}{true}, "10": 1.0}, `a:3:{i:10;d:1;s:1:"a";a:1:{i:0;b:1;}s:1:"b";N;}`},
+		{map[int]string{3: "c", -1: "a"}, `a:2:{i:-1;s:1:"a";i:3;s:1:"c";}`},
+		{&phpUser{Name: "kgo", Age: 3, Secret: "x", Ignored: "y"}, "O:7:\"phpUser\":3:{s:4:\"name\";s:3:\"kgo\";s:6:\"\x00*\x00age\";i:3;s:15:\"\x00phpUser\x00secret\";s:1:\"x\";}"},
+		{phpAdmin{phpUser: phpUser{Name: "root"}, Level: 9}, "O:8:\"phpAdmin\":4:{s:4:\"name\";s:4:\"root\";s:6:\"\x00*\x00age\";i:0;s:15:\"\x00phpUser\x00secret\";s:0:\"\";s:5:\"level\";i:9;}"},
+	}
+	for _, test := range tests {
+		res, err := KStr.Serialize(test.param)
+		if err != nil || string(res) != test.expected {
+			t.Errorf("Serialize(%v) expected %q, got %q, err %v", test.param, test.expected, res, err)
+			return
+		}
+	}
+
+	_, err := KStr.Serialize(map[string]interface{}{"fn": func() {}})
+	if err == nil {
+		t.Error("Serialize fail")
+		return
+	}
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	data := map[string]interface{}{"name": "kgo", "tags": []string{"a", "b"}, "age": 3}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KStr.Serialize(data)
+	}
+}
+
+func TestUnserialize(t *testing.T) {
+	//php: serialize(["a"=>1,"b"=>[1,2],"c"=>null,"d"=>true,"e"=>1.5,"f"=>"中文"])
+	data := []byte(`a:6:{s:1:"a";i:1;s:1:"b";a:2:{i:0;i:1;i:1;i:2;}s:1:"c";N;s:1:"d";b:1;s:1:"e";d:1.5;s:1:"f";s:6:"中文";}`)
+	var res interface{}
+	err := KStr.Unserialize(data, &res)
+	mp, ok := res.(map[string]interface{})
+	if err != nil || !ok || len(mp) != 6 || mp["a"] != 1 || mp["c"] != nil || mp["d"] != true || mp["e"] != 1.5 || mp["f"] != "中文" {
+		t.Error("Unserialize fail")
+		return
+	}
+	if list, ok := mp["b"].([]interface{}); !ok || len(list) != 2 || list[1] != 2 {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var typed map[string]interface{}
+	if err = KStr.Unserialize(data, &typed); err != nil || len(typed) != 6 {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var ints map[int]string
+	if err = KStr.Unserialize([]byte(`a:2:{i:5;s:1:"x";s:1:"7";s:1:"y";}`), &ints); err != nil || ints[5] != "x" || ints[7] != "y" {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var list []float64
+	if err = KStr.Unserialize([]byte(`a:3:{i:0;i:1;i:1;d:2.5;i:2;d:1.0E+25;}`), &list); err != nil || len(list) != 3 || list[2] != 1e25 {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var f float64
+	if err = KStr.Unserialize([]byte(`d:NAN;`), &f); err != nil || !math.IsNaN(f) {
+		t.Error("Unserialize fail")
+		return
+	}
+}
+
+func TestUnserializeObject(t *testing.T) {
+	//php: class User {public $name="kgo"; protected $age=3; private $secret="x";}
+	data := []byte("O:4:\"User\":4:{s:4:\"name\";s:3:\"kgo\";s:6:\"\x00*\x00age\";i:3;s:12:\"\x00User\x00secret\";s:1:\"x\";s:5:\"extra\";b:1;}")
+
+	var user phpUser
+	if err := KStr.Unserialize(data, &user); err != nil || user.Name != "kgo" || user.Age != 3 || user.Secret != "x" {
+		t.Error("Unserialize object fail")
+		return
+	}
+
+	var mp map[string]interface{}
+	if err := KStr.Unserialize(data, &mp); err != nil || mp["age"] != 3 || mp["secret"] != "x" {
+		t.Error("Unserialize object fail")
+		return
+	}
+
+	//往返
+	admin := &phpAdmin{phpUser: phpUser{Name: "root", Age: 30, Secret: "s"}, Level: 9}
+	res, _ := KStr.Serialize(admin)
+	var admin2 *phpAdmin
+	if err := KStr.Unserialize(res, &admin2); err != nil || *admin2 != *admin {
+		t.Error("Unserialize object fail")
+		return
+	}
+
+	//数组解码到结构体
+	if err := KStr.Unserialize([]byte(`a:2:{s:4:"NAME";s:1:"a";s:5:"level";i:2;}`), admin2); err != nil || admin2.Name != "a" || admin2.Level != 2 {
+		t.Error("Unserialize object fail")
+		return
+	}
+}
+
+func TestUnserializeInvalid(t *testing.T) {
+	var res interface{}
+	tests := []string{
+		"", "N", "x:1;", "b:2;", "i:a;", "d:x;", `s:5:"abc";`, `s:3:"abc"`, `s:-1:"";`,
+		"a:2:{i:0;i:1;}", "a:1:{d:1.5;i:1;}", "a:1:{i:0;i:1;", `O:4:"User:0:{}`, "r:1;", "i:1;i:2;",
+		//长度畸形或溢出
+		`s:9223372036854775807:"a";`, `s:9223372036854775806:"a";`, `s:99999999999999999999:"a";`,
+		`O:9223372036854775807:"User":0:{}`, `a:9223372036854775807:{}`, `s:1e3:"a";`,
+	}
+	for _, test := range tests {
+		if err := KStr.Unserialize([]byte(test), &res); err == nil {
+			t.Errorf("Unserialize(%q) should fail", test)
+			return
+		}
+	}
+
+	if err := KStr.Unserialize([]byte("i:1;"), res); err == nil {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var user phpUser
+	if err := KStr.Unserialize([]byte(`a:1:{s:4:"name";a:0:{}}`), &user); err == nil {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	var small int8
+	if err := KStr.Unserialize([]byte("i:300;"), &small); err == nil {
+		t.Error("Unserialize fail")
+		return
+	}
+
+	//未导出结构体的匿名指针为nil时无法分配,应报错而非panic
+	data := []byte(`a:2:{s:1:"X";i:5;s:1:"Y";i:6;}`)
+	var outer phpOuter
+	if err := KStr.Unserialize(data, &outer); err == nil {
+		t.Error("Unserialize fail")
+		return
+	}
+	outer = phpOuter{phpInner: &phpInner{}}
+	if err := KStr.Unserialize(data, &outer); err != nil || outer.X != 5 || outer.Y != 6 {
+		t.Error("Unserialize fail")
+		return
+	}
+}
+
+func BenchmarkUnserialize(b *testing.B) {
+	data := []byte(`a:6:{s:1:"a";i:1;s:1:"b";a:2:{i:0;i:1;i:1;i:2;}s:1:"c";N;s:1:"d";b:1;s:1:"e";d:1.5;s:1:"f";s:6:"中文";}`)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var res interface{}
+		_ = KStr.Unserialize(data, &res)
+	}
+}
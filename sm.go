@@ -0,0 +1,1027 @@
+package kgo
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// sm3IV SM3初始值
+var sm3IV = [8]uint32{0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600, 0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e}
+
+// sm4FK SM4系统参数
+var sm4FK = [4]uint32{0xa3b1bac6, 0x56aa3350, 0x677d9197, 0xb27022dc}
+
+// sm4CK SM4固定参数
+var sm4CK = [32]uint32{
+	0x00070e15, 0x1c232a31, 0x383f464d, 0x545b6269, 0x70777e85, 0x8c939aa1, 0xa8afb6bd, 0xc4cbd2d9,
+	0xe0e7eef5, 0xfc030a11, 0x181f262d, 0x343b4249, 0x50575e65, 0x6c737a81, 0x888f969d, 0xa4abb2b9,
+	0xc0c7ced5, 0xdce3eaf1, 0xf8ff060d, 0x141b2229, 0x30373e45, 0x4c535a61, 0x686f767d, 0x848b9299,
+	0xa0a7aeb5, 0xbcc3cad1, 0xd8dfe6ed, 0xf4fb0209, 0x10171e25, 0x2c333a41, 0x484f565d, 0x646b7279,
+}
+
+// sm4Sbox SM4的S盒
+var sm4Sbox = [256]byte{
+	0xd6, 0x90, 0xe9, 0xfe, 0xcc, 0xe1, 0x3d, 0xb7, 0x16, 0xb6, 0x14, 0xc2, 0x28, 0xfb, 0x2c, 0x05,
+	0x2b, 0x67, 0x9a, 0x76, 0x2a, 0xbe, 0x04, 0xc3, 0xaa, 0x44, 0x13, 0x26, 0x49, 0x86, 0x06, 0x99,
+	0x9c, 0x42, 0x50, 0xf4, 0x91, 0xef, 0x98, 0x7a, 0x33, 0x54, 0x0b, 0x43, 0xed, 0xcf, 0xac, 0x62,
+	0xe4, 0xb3, 0x1c, 0xa9, 0xc9, 0x08, 0xe8, 0x95, 0x80, 0xdf, 0x94, 0xfa, 0x75, 0x8f, 0x3f, 0xa6,
+	0x47, 0x07, 0xa7, 0xfc, 0xf3, 0x73, 0x17, 0xba, 0x83, 0x59, 0x3c, 0x19, 0xe6, 0x85, 0x4f, 0xa8,
+	0x68, 0x6b, 0x81, 0xb2, 0x71, 0x64, 0xda, 0x8b, 0xf8, 0xeb, 0x0f, 0x4b, 0x70, 0x56, 0x9d, 0x35,
+	0x1e, 0x24, 0x0e, 0x5e, 0x63, 0x58, 0xd1, 0xa2, 0x25, 0x22, 0x7c, 0x3b, 0x01, 0x21, 0x78, 0x87,
+	0xd4, 0x00, 0x46, 0x57, 0x9f, 0xd3, 0x27, 0x52, 0x4c, 0x36, 0x02, 0xe7, 0xa0, 0xc4, 0xc8, 0x9e,
+	0xea, 0xbf, 0x8a, 0xd2, 0x40, 0xc7, 0x38, 0xb5, 0xa3, 0xf7, 0xf2, 0xce, 0xf9, 0x61, 0x15, 0xa1,
+	0xe0, 0xae, 0x5d, 0xa4, 0x9b, 0x34, 0x1a, 0x55, 0xad, 0x93, 0x32, 0x30, 0xf5, 0x8c, 0xb1, 0xe3,
+	0x1d, 0xf6, 0xe2, 0x2e, 0x82, 0x66, 0xca, 0x60, 0xc0, 0x29, 0x23, 0xab, 0x0d, 0x53, 0x4e, 0x6f,
+	0xd5, 0xdb, 0x37, 0x45, 0xde, 0xfd, 0x8e, 0x2f, 0x03, 0xff, 0x6a, 0x72, 0x6d, 0x6c, 0x5b, 0x51,
+	0x8d, 0x1b, 0xaf, 0x92, 0xbb, 0xdd, 0xbc, 0x7f, 0x11, 0xd9, 0x5c, 0x41, 0x1f, 0x10, 0x5a, 0xd8,
+	0x0a, 0xc1, 0x31, 0x88, 0xa5, 0xcd, 0x7b, 0xbd, 0x2d, 0x74, 0xd0, 0x12, 0xb8, 0xe5, 0xb4, 0xb0,
+	0x89, 0x69, 0x97, 0x4a, 0x0c, 0x96, 0x77, 0x7e, 0x65, 0xb9, 0xf1, 0x09, 0xc5, 0x6e, 0xc6, 0x84,
+	0x18, 0xf0, 0x7d, 0xec, 0x3a, 0xdc, 0x4d, 0x20, 0x79, 0xee, 0x5f, 0x3e, 0xd7, 0xcb, 0x39, 0x48,
+}
+
+// sm2P256 SM2推荐曲线sm2p256v1
+var sm2P256 = &sm2Curve{
+	P:  sm2HexInt("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF"),
+	A:  sm2HexInt("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFC"),
+	B:  sm2HexInt("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93"),
+	N:  sm2HexInt("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123"),
+	Gx: sm2HexInt("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7"),
+	Gy: sm2HexInt("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0"),
+}
+
+var (
+	// oidEcPublicKey 椭圆曲线公钥算法的OID
+	oidEcPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	// oidSm2P256 SM2推荐曲线的OID
+	oidSm2P256 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+)
+
+// sm2HexInt 将16进制字符串转为大整数.
+func sm2HexInt(str string) *big.Int {
+	n, _ := new(big.Int).SetString(str, 16)
+	return n
+}
+
+// newSm3 创建SM3散列.
+func newSm3() hash.Hash {
+	d := new(sm3Digest)
+	d.Reset()
+	return d
+}
+
+// Reset 重置散列状态.
+func (d *sm3Digest) Reset() {
+	d.h = sm3IV
+	d.nbuf = 0
+	d.length = 0
+}
+
+// Size 散列结果的字节数.
+func (d *sm3Digest) Size() int {
+	return 32
+}
+
+// BlockSize 分组字节数.
+func (d *sm3Digest) BlockSize() int {
+	return 64
+}
+
+// Write 写入数据.
+func (d *sm3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+	if d.nbuf > 0 {
+		m := copy(d.buf[d.nbuf:], p)
+		d.nbuf += m
+		p = p[m:]
+		if d.nbuf == 64 {
+			d.block(d.buf[:])
+			d.nbuf = 0
+		}
+	}
+	for len(p) >= 64 {
+		d.block(p[:64])
+		p = p[64:]
+	}
+	if len(p) > 0 {
+		d.nbuf = copy(d.buf[:], p)
+	}
+
+	return n, nil
+}
+
+// Sum 将散列结果追加到b后返回,不改变当前状态.
+func (d *sm3Digest) Sum(b []byte) []byte {
+	dup := *d
+	length := dup.length << 3
+
+	//填充:1个0x80,若干0x00,8字节的消息位长度
+	var pad [72]byte
+	pad[0] = 0x80
+	padLen := 56 - int(dup.length%64)
+	if padLen <= 0 {
+		padLen += 64
+	}
+	binary.BigEndian.PutUint64(pad[padLen:], length)
+	_, _ = dup.Write(pad[:padLen+8])
+
+	var out [32]byte
+	for i, v := range dup.h {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+
+	return append(b, out[:]...)
+}
+
+// block 压缩一个64字节的分组.
+func (d *sm3Digest) block(p []byte) {
+	var w [68]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[i*4:])
+	}
+	for i := 16; i < 68; i++ {
+		x := w[i-16] ^ w[i-9] ^ bits.RotateLeft32(w[i-3], 15)
+		w[i] = x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
+	}
+
+	a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+	for j := 0; j < 64; j++ {
+		var t, ff, gg uint32
+		if j < 16 {
+			t = 0x79cc4519
+			ff = a ^ b ^ c
+			gg = e ^ f ^ g
+		} else {
+			t = 0x7a879d8a
+			ff = (a & b) | (a & c) | (b & c)
+			gg = (e & f) | (^e & g)
+		}
+
+		a12 := bits.RotateLeft32(a, 12)
+		ss1 := bits.RotateLeft32(a12+e+bits.RotateLeft32(t, j%32), 7)
+		ss2 := ss1 ^ a12
+		tt1 := ff + dd + ss2 + (w[j] ^ w[j+4])
+		tt2 := gg + h + ss1 + w[j]
+		dd = c
+		c = bits.RotateLeft32(b, 9)
+		b = a
+		a = tt1
+		h = g
+		g = bits.RotateLeft32(f, 19)
+		f = e
+		e = tt2 ^ bits.RotateLeft32(tt2, 9) ^ bits.RotateLeft32(tt2, 17)
+	}
+
+	d.h[0] ^= a
+	d.h[1] ^= b
+	d.h[2] ^= c
+	d.h[3] ^= dd
+	d.h[4] ^= e
+	d.h[5] ^= f
+	d.h[6] ^= g
+	d.h[7] ^= h
+}
+
+// sm3Sum 计算SM3散列值.
+func sm3Sum(data ...[]byte) []byte {
+	h := newSm3()
+	for _, v := range data {
+		h.Write(v)
+	}
+	return h.Sum(nil)
+}
+
+// Sm3 计算SM3散列值(GB/T 32905),返回64位16进制字符串.
+func (ke *LkkEncrypt) Sm3(data []byte) string {
+	return hex.EncodeToString(sm3Sum(data))
+}
+
+// NewSm3 创建SM3散列的hash.Hash,用于流式计算或与hmac等组合.
+func (ke *LkkEncrypt) NewSm3() hash.Hash {
+	return newSm3()
+}
+
+// HmacSm3 HMAC-SM3,返回64位16进制字符串.
+func (ke *LkkEncrypt) HmacSm3(data, secret []byte) string {
+	h := hmac.New(newSm3, secret)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sm4Tau SM4的非线性变换τ.
+func sm4Tau(x uint32) uint32 {
+	return uint32(sm4Sbox[x>>24])<<24 | uint32(sm4Sbox[x>>16&0xff])<<16 | uint32(sm4Sbox[x>>8&0xff])<<8 | uint32(sm4Sbox[x&0xff])
+}
+
+// newSm4Cipher 创建SM4分组密码,key长16.
+func newSm4Cipher(key []byte) (cipher.Block, error) {
+	if len(key) != 16 {
+		return nil, fmt.Errorf("invalid sm4 key size %d", len(key))
+	}
+
+	c := new(sm4Cipher)
+	var k [4]uint32
+	for i := 0; i < 4; i++ {
+		k[i] = binary.BigEndian.Uint32(key[i*4:]) ^ sm4FK[i]
+	}
+	for i := 0; i < 32; i++ {
+		b := sm4Tau(k[1] ^ k[2] ^ k[3] ^ sm4CK[i])
+		c.rk[i] = k[0] ^ b ^ bits.RotateLeft32(b, 13) ^ bits.RotateLeft32(b, 23)
+		k[0], k[1], k[2], k[3] = k[1], k[2], k[3], c.rk[i]
+	}
+
+	return c, nil
+}
+
+// BlockSize 分组字节数.
+func (c *sm4Cipher) BlockSize() int {
+	return 16
+}
+
+// crypt 使用轮密钥处理一个分组,decrypt为true时逆序使用轮密钥.
+func (c *sm4Cipher) crypt(dst, src []byte, decrypt bool) {
+	var x [4]uint32
+	for i := 0; i < 4; i++ {
+		x[i] = binary.BigEndian.Uint32(src[i*4:])
+	}
+	for i := 0; i < 32; i++ {
+		rk := c.rk[i]
+		if decrypt {
+			rk = c.rk[31-i]
+		}
+		b := sm4Tau(x[1] ^ x[2] ^ x[3] ^ rk)
+		b = x[0] ^ b ^ bits.RotateLeft32(b, 2) ^ bits.RotateLeft32(b, 10) ^ bits.RotateLeft32(b, 18) ^ bits.RotateLeft32(b, 24)
+		x[0], x[1], x[2], x[3] = x[1], x[2], x[3], b
+	}
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint32(dst[i*4:], x[3-i])
+	}
+}
+
+// Encrypt 加密一个分组.
+func (c *sm4Cipher) Encrypt(dst, src []byte) {
+	c.crypt(dst, src, false)
+}
+
+// Decrypt 解密一个分组.
+func (c *sm4Cipher) Decrypt(dst, src []byte) {
+	c.crypt(dst, src, true)
+}
+
+// sm4Encrypt SM4加密.
+// clearText为明文;key为密钥,长16;mode为模式,枚举值(ECB,CBC,CTR);paddingType为填充方式.
+func (ke *LkkEncrypt) sm4Encrypt(clearText, key []byte, mode string, paddingType ...LkkPKCSType) ([]byte, error) {
+	block, err := newSm4Cipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ke.blockEncrypt(block, clearText, mode, paddingType...)
+}
+
+// sm4Decrypt SM4解密.
+// cipherText为密文;key为密钥,长16;mode为模式,枚举值(ECB,CBC,CTR);paddingType为填充方式.
+func (ke *LkkEncrypt) sm4Decrypt(cipherText, key []byte, mode string, paddingType ...LkkPKCSType) ([]byte, error) {
+	block, err := newSm4Cipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ke.blockDecrypt("sm4", block, cipherText, mode, paddingType...)
+}
+
+// Sm4ECBEncrypt SM4-ECB电子密码本(Electronic codebook)模式加密.相同的明文分组得到相同的密文分组,仅用于兼容.
+// clearText为明文;key为密钥,长16;paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) Sm4ECBEncrypt(clearText, key []byte, paddingType ...LkkPKCSType) ([]byte, error) {
+	return ke.sm4Encrypt(clearText, key, "ECB", paddingType...)
+}
+
+// Sm4ECBDecrypt SM4-ECB电子密码本(Electronic codebook)模式解密.
+// cipherText为密文;key为密钥,长16;paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) Sm4ECBDecrypt(cipherText, key []byte, paddingType ...LkkPKCSType) ([]byte, error) {
+	return ke.sm4Decrypt(cipherText, key, "ECB", paddingType...)
+}
+
+// Sm4CBCEncrypt SM4-CBC密码分组链接(Cipher-block chaining)模式加密,结果以16字节随机初始化向量开头.
+// clearText为明文;key为密钥,长16;paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) Sm4CBCEncrypt(clearText, key []byte, paddingType ...LkkPKCSType) ([]byte, error) {
+	return ke.sm4Encrypt(clearText, key, "CBC", paddingType...)
+}
+
+// Sm4CBCDecrypt SM4-CBC密码分组链接(Cipher-block chaining)模式解密.
+// cipherText为密文;key为密钥,长16;paddingType为填充方式,枚举(PKCS_NONE,PKCS_ZERO,PKCS_SEVEN),默认PKCS_SEVEN.
+func (ke *LkkEncrypt) Sm4CBCDecrypt(cipherText, key []byte, paddingType ...LkkPKCSType) ([]byte, error) {
+	return ke.sm4Decrypt(cipherText, key, "CBC", paddingType...)
+}
+
+// Sm4CTREncrypt SM4-CTR计算器(Counter)模式加密,结果以16字节随机初始化向量开头.
+// clearText为明文;key为密钥,长16.
+func (ke *LkkEncrypt) Sm4CTREncrypt(clearText, key []byte) ([]byte, error) {
+	return ke.sm4Encrypt(clearText, key, "CTR", PKCS_NONE)
+}
+
+// Sm4CTRDecrypt SM4-CTR计算器(Counter)模式解密.
+// cipherText为密文;key为密钥,长16.
+func (ke *LkkEncrypt) Sm4CTRDecrypt(cipherText, key []byte) ([]byte, error) {
+	return ke.sm4Decrypt(cipherText, key, "CTR", PKCS_NONE)
+}
+
+// isOnCurve 点是否在曲线上.
+func (c *sm2Curve) isOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Sign() < 0 || y.Cmp(c.P) >= 0 {
+		return false
+	}
+
+	//y^2 = x^3 + ax + b
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, c.P)
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, new(big.Int).Mul(c.A, x))
+	x3.Add(x3, c.B)
+	x3.Mod(x3, c.P)
+
+	return x3.Cmp(y2) == 0
+}
+
+// add 点加,x为nil表示无穷远点.
+func (c *sm2Curve) add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	} else if x2 == nil {
+		return x1, y1
+	}
+
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) != 0 || y1.Sign() == 0 {
+			return nil, nil
+		}
+		//λ = (3x^2 + a) / 2y
+		num := new(big.Int).Mul(x1, x1)
+		num.Mul(num, big.NewInt(3))
+		num.Add(num, c.A)
+		den := new(big.Int).Lsh(y1, 1)
+		den.ModInverse(den.Mod(den, c.P), c.P)
+		lambda = num.Mul(num, den)
+	} else {
+		//λ = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		den.ModInverse(den.Mod(den, c.P), c.P)
+		lambda = num.Mul(num, den)
+	}
+	lambda.Mod(lambda, c.P)
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, c.P)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, y1)
+	y3.Mod(y3, c.P)
+
+	return x3, y3
+}
+
+// newSm2Field 创建曲线c的素域运算参数,p须小于2^256.
+func newSm2Field(c *sm2Curve) *sm2Field {
+	f := &sm2Field{pBig: c.P, pMinus: new(big.Int).Sub(c.P, big.NewInt(2))}
+	f.p.setBytes(bigIntBytes(c.P, 32))
+
+	//牛顿迭代求p^-1 mod 2^64
+	inv := f.p[0]
+	for i := 0; i < 5; i++ {
+		inv *= 2 - f.p[0]*inv
+	}
+	f.pInv = -inv
+
+	r2 := new(big.Int).Lsh(big.NewInt(1), 512)
+	f.r2.setBytes(bigIntBytes(r2.Mod(r2, c.P), 32))
+	f.one = f.fromBig(big.NewInt(1))
+	f.a = f.fromBig(c.A)
+	f.b3 = f.fromBig(new(big.Int).Mul(c.B, big.NewInt(3)))
+
+	return f
+}
+
+// setBytes 由32字节大端序数据设置元素的值.
+func (e *sm2Element) setBytes(buf []byte) {
+	for i := 0; i < 4; i++ {
+		e[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+}
+
+// bytes 返回元素的32字节大端序表示.
+func (e *sm2Element) bytes() []byte {
+	buf := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint64(buf[24-8*i:], e[i])
+	}
+	return buf
+}
+
+// isZero 元素是否为0,常数时间.
+func (e *sm2Element) isZero() bool {
+	return e[0]|e[1]|e[2]|e[3] == 0
+}
+
+// fromBig 将大整数转为Montgomery形式的元素.
+func (f *sm2Field) fromBig(n *big.Int) sm2Element {
+	var e sm2Element
+	e.setBytes(bigIntBytes(new(big.Int).Mod(n, f.pBig), 32))
+	f.mul(&e, &e, &f.r2)
+	return e
+}
+
+// toBig 将Montgomery形式的元素转为大整数.
+func (f *sm2Field) toBig(e *sm2Element) *big.Int {
+	var t sm2Element
+	f.mul(&t, e, &sm2Element{1})
+	return new(big.Int).SetBytes(t.bytes())
+}
+
+// reduce 将t+carry*2^256(小于2p)约减到[0,p),常数时间.
+func (f *sm2Field) reduce(z *sm2Element, t *sm2Element, carry uint64) {
+	var r sm2Element
+	var b uint64
+	for i := 0; i < 4; i++ {
+		r[i], b = bits.Sub64(t[i], f.p[i], b)
+	}
+	_, b = bits.Sub64(carry, 0, b)
+
+	//有借位时t<p,取t
+	mask := -b
+	for i := 0; i < 4; i++ {
+		z[i] = t[i]&mask | r[i]&^mask
+	}
+}
+
+// add z = x + y mod p.
+func (f *sm2Field) add(z, x, y *sm2Element) {
+	var t sm2Element
+	var c uint64
+	for i := 0; i < 4; i++ {
+		t[i], c = bits.Add64(x[i], y[i], c)
+	}
+	f.reduce(z, &t, c)
+}
+
+// sub z = x - y mod p.
+func (f *sm2Field) sub(z, x, y *sm2Element) {
+	var t sm2Element
+	var b, c uint64
+	for i := 0; i < 4; i++ {
+		t[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	mask := -b
+	for i := 0; i < 4; i++ {
+		z[i], c = bits.Add64(t[i], f.p[i]&mask, c)
+	}
+}
+
+// mul Montgomery乘法 z = x * y * 2^-256 mod p.
+func (f *sm2Field) mul(z, x, y *sm2Element) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		//t += x * y[i]
+		var c, c1, hi, lo uint64
+		for j := 0; j < 4; j++ {
+			hi, lo = bits.Mul64(x[j], y[i])
+			lo, c1 = bits.Add64(lo, t[j], 0)
+			hi += c1
+			lo, c1 = bits.Add64(lo, c, 0)
+			hi += c1
+			t[j], c = lo, hi
+		}
+		t[4], c1 = bits.Add64(t[4], c, 0)
+		t[5] = c1
+
+		//t = (t + m*p) / 2^64
+		m := t[0] * f.pInv
+		hi, lo = bits.Mul64(m, f.p[0])
+		_, c1 = bits.Add64(lo, t[0], 0)
+		c = hi + c1
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, f.p[j])
+			lo, c1 = bits.Add64(lo, t[j], 0)
+			hi += c1
+			lo, c1 = bits.Add64(lo, c, 0)
+			hi += c1
+			t[j-1], c = lo, hi
+		}
+		t[3], c1 = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + c1
+	}
+
+	f.reduce(z, &sm2Element{t[0], t[1], t[2], t[3]}, t[4])
+}
+
+// invert z = x^-1 mod p,按费马小定理计算x^(p-2);指数为公开的常数.
+func (f *sm2Field) invert(z, x *sm2Element) {
+	res := f.one
+	for i := f.pMinus.BitLen() - 1; i >= 0; i-- {
+		f.mul(&res, &res, &res)
+		if f.pMinus.Bit(i) == 1 {
+			f.mul(&res, &res, x)
+		}
+	}
+	*z = res
+}
+
+// pointAdd 射影坐标的完备加法公式(Renes-Costello-Batina算法1),适用于任意a,
+// 点相同或含无穷远点时同样成立,无需分支.
+func (f *sm2Field) pointAdd(r, p, q *sm2Point) {
+	var t0, t1, t2, t3, t4, t5, x3, y3, z3 sm2Element
+	f.mul(&t0, &p.x, &q.x)
+	f.mul(&t1, &p.y, &q.y)
+	f.mul(&t2, &p.z, &q.z)
+	f.add(&t3, &p.x, &p.y)
+	f.add(&t4, &q.x, &q.y)
+	f.mul(&t3, &t3, &t4)
+	f.add(&t4, &t0, &t1)
+	f.sub(&t3, &t3, &t4)
+	f.add(&t4, &p.x, &p.z)
+	f.add(&t5, &q.x, &q.z)
+	f.mul(&t4, &t4, &t5)
+	f.add(&t5, &t0, &t2)
+	f.sub(&t4, &t4, &t5)
+	f.add(&t5, &p.y, &p.z)
+	f.add(&x3, &q.y, &q.z)
+	f.mul(&t5, &t5, &x3)
+	f.add(&x3, &t1, &t2)
+	f.sub(&t5, &t5, &x3)
+	f.mul(&z3, &f.a, &t4)
+	f.mul(&x3, &f.b3, &t2)
+	f.add(&z3, &x3, &z3)
+	f.sub(&x3, &t1, &z3)
+	f.add(&z3, &t1, &z3)
+	f.mul(&y3, &x3, &z3)
+	f.add(&t1, &t0, &t0)
+	f.add(&t1, &t1, &t0)
+	f.mul(&t2, &f.a, &t2)
+	f.mul(&t4, &f.b3, &t4)
+	f.add(&t1, &t1, &t2)
+	f.sub(&t2, &t0, &t2)
+	f.mul(&t2, &f.a, &t2)
+	f.add(&t4, &t4, &t2)
+	f.mul(&t0, &t1, &t4)
+	f.add(&y3, &y3, &t0)
+	f.mul(&t0, &t5, &t4)
+	f.mul(&x3, &t3, &x3)
+	f.sub(&x3, &x3, &t0)
+	f.mul(&t0, &t3, &t1)
+	f.mul(&z3, &t5, &z3)
+	f.add(&z3, &z3, &t0)
+	r.x, r.y, r.z = x3, y3, z3
+}
+
+// pointSelect 常数时间地从table中取出第idx个点.
+func pointSelect(r *sm2Point, table *[16]sm2Point, idx uint64) {
+	*r = sm2Point{}
+	for i := range table {
+		//v为0时mask全为1
+		v := uint64(i) ^ idx
+		mask := ((v | -v) >> 63) - 1
+		for j := 0; j < 4; j++ {
+			r.x[j] |= table[i].x[j] & mask
+			r.y[j] |= table[i].y[j] & mask
+			r.z[j] |= table[i].z[j] & mask
+		}
+	}
+}
+
+// scalarMult 倍点运算k*(x,y),结果为无穷远点时返回nil.
+// 使用4位固定窗口和常数时间的域运算,k不超过32字节时运算过程与其取值无关.
+func (c *sm2Curve) scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	f := newSm2Field(c)
+
+	//预计算0P到15P
+	var table [16]sm2Point
+	table[0].y = f.one
+	table[1] = sm2Point{x: f.fromBig(x), y: f.fromBig(y), z: f.one}
+	for i := 2; i < 16; i++ {
+		f.pointAdd(&table[i], &table[i-1], &table[1])
+	}
+
+	var r, t sm2Point
+	r.y = f.one
+	for _, b := range bigIntBytes(k, 32) {
+		for _, w := range []uint64{uint64(b >> 4), uint64(b & 15)} {
+			for i := 0; i < 4; i++ {
+				f.pointAdd(&r, &r, &r)
+			}
+			pointSelect(&t, &table, w)
+			f.pointAdd(&r, &r, &t)
+		}
+	}
+
+	if r.z.isZero() {
+		return nil, nil
+	}
+	var zInv sm2Element
+	f.invert(&zInv, &r.z)
+	f.mul(&r.x, &r.x, &zInv)
+	f.mul(&r.y, &r.y, &zInv)
+
+	return f.toBig(&r.x), f.toBig(&r.y)
+}
+
+// byteLen 曲线元素的字节数.
+func (c *sm2Curve) byteLen() int {
+	return (c.P.BitLen() + 7) / 8
+}
+
+// randScalar 生成[1, n-2]内的随机数,可用作私钥(d+1须可逆)或签名的随机数k.
+func (c *sm2Curve) randScalar(random io.Reader) (*big.Int, error) {
+	buf := make([]byte, c.byteLen()+8)
+	if _, err := io.ReadFull(random, buf); err != nil {
+		return nil, err
+	}
+
+	nMinus2 := new(big.Int).Sub(c.N, big.NewInt(2))
+	k := new(big.Int).SetBytes(buf)
+	k.Mod(k, nMinus2)
+
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// marshalPoint 将点编码为未压缩格式04||x||y.
+func (c *sm2Curve) marshalPoint(x, y *big.Int) []byte {
+	size := c.byteLen()
+	res := make([]byte, 1, 1+2*size)
+	res[0] = 4
+	res = append(res, bigIntBytes(x, size)...)
+	return append(res, bigIntBytes(y, size)...)
+}
+
+// unmarshalPoint 解析未压缩格式的点,并检查是否在曲线上.
+func (c *sm2Curve) unmarshalPoint(data []byte) (*big.Int, *big.Int, error) {
+	size := c.byteLen()
+	if len(data) != 1+2*size || data[0] != 4 {
+		return nil, nil, errors.New("invalid sm2 point")
+	}
+
+	x := new(big.Int).SetBytes(data[1 : 1+size])
+	y := new(big.Int).SetBytes(data[1+size:])
+	if !c.isOnCurve(x, y) {
+		return nil, nil, errors.New("sm2 point is not on curve")
+	}
+
+	return x, y, nil
+}
+
+// newSm2PrivateKey 由私钥d创建SM2私钥.
+func newSm2PrivateKey(c *sm2Curve, d *big.Int) *sm2PrivateKey {
+	x, y := c.scalarMult(c.Gx, c.Gy, d)
+	return &sm2PrivateKey{sm2PublicKey: sm2PublicKey{curve: c, X: x, Y: y}, D: d}
+}
+
+// sm2Za 计算用户身份的杂凑值Z = SM3(ENTL || ID || a || b || xG || yG || xA || yA).
+func sm2Za(pub *sm2PublicKey, uid []byte) []byte {
+	c := pub.curve
+	size := c.byteLen()
+	entl := []byte{byte(len(uid) * 8 >> 8), byte(len(uid) * 8)}
+
+	return sm3Sum(entl, uid, bigIntBytes(c.A, size), bigIntBytes(c.B, size), bigIntBytes(c.Gx, size),
+		bigIntBytes(c.Gy, size), bigIntBytes(pub.X, size), bigIntBytes(pub.Y, size))
+}
+
+// sm2SignWithK 使用随机数k对消息摘要e签名,r或s不合法时返回nil,需换k重试;私钥不合法时返回错误.
+func sm2SignWithK(priv *sm2PrivateKey, e, k *big.Int) (*big.Int, *big.Int, error) {
+	c := priv.curve
+
+	//(1 + d)^-1 mod n,d = n-1时不存在
+	d1 := new(big.Int).Add(priv.D, big.NewInt(1))
+	if d1.ModInverse(d1, c.N) == nil {
+		return nil, nil, errors.New("invalid sm2 private key")
+	}
+
+	x1, _ := c.scalarMult(c.Gx, c.Gy, k)
+
+	//r = (e + x1) mod n
+	r := new(big.Int).Add(e, x1)
+	r.Mod(r, c.N)
+	if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(c.N) == 0 {
+		return nil, nil, nil
+	}
+
+	//s = ((1 + d)^-1 * (k - r*d)) mod n
+	s := new(big.Int).Mul(r, priv.D)
+	s.Sub(k, s)
+	s.Mul(s, d1)
+	s.Mod(s, c.N)
+	if s.Sign() == 0 {
+		return nil, nil, nil
+	}
+
+	return r, s, nil
+}
+
+// sm2Sign SM2签名.
+func sm2Sign(priv *sm2PrivateKey, data, uid []byte) (*big.Int, *big.Int, error) {
+	e := new(big.Int).SetBytes(sm3Sum(sm2Za(&priv.sm2PublicKey, uid), data))
+	for {
+		k, err := priv.curve.randScalar(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, s, err := sm2SignWithK(priv, e, k)
+		if err != nil {
+			return nil, nil, err
+		} else if r != nil {
+			return r, s, nil
+		}
+	}
+}
+
+// sm2Verify SM2验签.
+func sm2Verify(pub *sm2PublicKey, data, uid []byte, r, s *big.Int) bool {
+	c := pub.curve
+	if r.Sign() <= 0 || r.Cmp(c.N) >= 0 || s.Sign() <= 0 || s.Cmp(c.N) >= 0 {
+		return false
+	}
+
+	//t = (r + s) mod n
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, c.N)
+	if t.Sign() == 0 {
+		return false
+	}
+
+	//(x1, y1) = sG + tP
+	e := new(big.Int).SetBytes(sm3Sum(sm2Za(pub, uid), data))
+	x1, y1 := c.scalarMult(c.Gx, c.Gy, s)
+	x2, y2 := c.scalarMult(pub.X, pub.Y, t)
+	x1, _ = c.add(x1, y1, x2, y2)
+	if x1 == nil {
+		return false
+	}
+
+	//R = (e + x1) mod n
+	e.Add(e, x1)
+	e.Mod(e, c.N)
+
+	return e.Cmp(r) == 0
+}
+
+// sm2Kdf SM2密钥派生函数,由z生成length字节的密钥.
+func sm2Kdf(length int, z ...[]byte) []byte {
+	res := make([]byte, 0, length+32)
+	ct := make([]byte, 4)
+	for i := uint32(1); len(res) < length; i++ {
+		binary.BigEndian.PutUint32(ct, i)
+		res = append(res, sm3Sum(append(z, ct)...)...)
+	}
+
+	return res[:length]
+}
+
+// sm2EncryptWithK 使用随机数k加密,结果为C1||C3||C2;kP为无穷远点或派生密钥全为0时返回nil.
+func sm2EncryptWithK(pub *sm2PublicKey, msg []byte, k *big.Int) []byte {
+	c := pub.curve
+	size := c.byteLen()
+	x1, y1 := c.scalarMult(c.Gx, c.Gy, k)
+	x2, y2 := c.scalarMult(pub.X, pub.Y, k)
+	if x2 == nil {
+		return nil
+	}
+
+	x2b, y2b := bigIntBytes(x2, size), bigIntBytes(y2, size)
+	t := sm2Kdf(len(msg), x2b, y2b)
+	if len(msg) > 0 && len(bytes.Trim(t, "\x00")) == 0 {
+		return nil
+	}
+
+	res := c.marshalPoint(x1, y1)
+	res = append(res, sm3Sum(x2b, msg, y2b)...)
+	for i, v := range msg {
+		t[i] ^= v
+	}
+
+	return append(res, t...)
+}
+
+// sm2Decrypt SM2解密,cipherText为C1||C3||C2.
+func sm2Decrypt(priv *sm2PrivateKey, cipherText []byte) ([]byte, error) {
+	c := priv.curve
+	size := c.byteLen()
+	if len(cipherText) < 1+2*size+32 {
+		return nil, errors.New("cipherText too short")
+	}
+
+	x1, y1, err := c.unmarshalPoint(cipherText[:1+2*size])
+	if err != nil {
+		return nil, err
+	}
+
+	x2, y2 := c.scalarMult(x1, y1, priv.D)
+	if x2 == nil {
+		return nil, ErrAuthFailed
+	}
+
+	x2b, y2b := bigIntBytes(x2, size), bigIntBytes(y2, size)
+	c3 := cipherText[1+2*size : 1+2*size+32]
+	c2 := cipherText[1+2*size+32:]
+	msg := sm2Kdf(len(c2), x2b, y2b)
+	for i, v := range c2 {
+		msg[i] ^= v
+	}
+
+	if !hmac.Equal(sm3Sum(x2b, msg, y2b), c3) {
+		return nil, ErrAuthFailed
+	}
+
+	return msg, nil
+}
+
+// sm2AlgorithmIdentifier SM2密钥的算法标识.
+func sm2AlgorithmIdentifier() pkix.AlgorithmIdentifier {
+	param, _ := asn1.Marshal(oidSm2P256)
+	return pkix.AlgorithmIdentifier{Algorithm: oidEcPublicKey, Parameters: asn1.RawValue{FullBytes: param}}
+}
+
+// marshalSm2Keys 将SM2私钥编码为PKCS8格式,公钥编码为PKIX格式的pem.
+func marshalSm2Keys(priv *sm2PrivateKey) (private []byte, public []byte, err error) {
+	c := priv.curve
+	pubBytes := c.marshalPoint(priv.X, priv.Y)
+	pubKey := asn1.BitString{Bytes: pubBytes, BitLength: len(pubBytes) * 8}
+
+	var ecDer, der []byte
+	if ecDer, err = asn1.Marshal(sm2EcPrivateKey{Version: 1, PrivateKey: bigIntBytes(priv.D, c.byteLen()), PublicKey: pubKey}); err != nil {
+		return
+	}
+	if der, err = asn1.Marshal(sm2Pkcs8Key{Algo: sm2AlgorithmIdentifier(), PrivateKey: ecDer}); err != nil {
+		return
+	}
+	private = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	if der, err = asn1.Marshal(sm2PkixKey{Algo: sm2AlgorithmIdentifier(), PublicKey: pubKey}); err != nil {
+		return
+	}
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	return
+}
+
+// isSm2Algorithm 算法标识是否为SM2曲线.
+func isSm2Algorithm(algo pkix.AlgorithmIdentifier) bool {
+	var oid asn1.ObjectIdentifier
+	if !algo.Algorithm.Equal(oidEcPublicKey) {
+		return false
+	} else if _, err := asn1.Unmarshal(algo.Parameters.FullBytes, &oid); err != nil {
+		return false
+	}
+
+	return oid.Equal(oidSm2P256)
+}
+
+// parseSm2PrivateKey 解析pem格式的SM2私钥,支持PKCS8和SEC1(EC PRIVATE KEY)格式.
+func parseSm2PrivateKey(key []byte) (*sm2PrivateKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("private key error")
+	}
+
+	der := block.Bytes
+	if block.Type != "EC PRIVATE KEY" {
+		var p8 sm2Pkcs8Key
+		if _, err := asn1.Unmarshal(der, &p8); err != nil {
+			return nil, err
+		} else if !isSm2Algorithm(p8.Algo) {
+			return nil, errors.New("not a sm2 private key")
+		}
+		der = p8.PrivateKey
+	}
+
+	var ecKey sm2EcPrivateKey
+	if _, err := asn1.Unmarshal(der, &ecKey); err != nil {
+		return nil, err
+	} else if ecKey.NamedCurveOID != nil && !ecKey.NamedCurveOID.Equal(oidSm2P256) {
+		return nil, errors.New("not a sm2 private key")
+	}
+
+	//d须在[1, n-2]内,否则签名时d+1不可逆
+	d := new(big.Int).SetBytes(ecKey.PrivateKey)
+	if d.Sign() <= 0 || d.Cmp(new(big.Int).Sub(sm2P256.N, big.NewInt(1))) >= 0 {
+		return nil, errors.New("invalid sm2 private key")
+	}
+
+	return newSm2PrivateKey(sm2P256, d), nil
+}
+
+// parseSm2PublicKey 解析pem格式(PKIX)的SM2公钥.
+func parseSm2PublicKey(key []byte) (*sm2PublicKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("public key error")
+	}
+
+	var pkix sm2PkixKey
+	if _, err := asn1.Unmarshal(block.Bytes, &pkix); err != nil {
+		return nil, err
+	} else if !isSm2Algorithm(pkix.Algo) {
+		return nil, errors.New("not a sm2 public key")
+	}
+
+	x, y, err := sm2P256.unmarshalPoint(pkix.PublicKey.RightAlign())
+	if err != nil {
+		return nil, err
+	}
+
+	return &sm2PublicKey{curve: sm2P256, X: x, Y: y}, nil
+}
+
+// GenerateSm2Keys 生成SM2密钥对(GB/T 32918),私钥为PKCS8格式,公钥为PKIX格式的pem,与OpenSSL兼容.
+func (ke *LkkEncrypt) GenerateSm2Keys() (private []byte, public []byte, err error) {
+	var d *big.Int
+	if d, err = sm2P256.randScalar(rand.Reader); err != nil {
+		return
+	}
+
+	return marshalSm2Keys(newSm2PrivateKey(sm2P256, d))
+}
+
+// Sm2Encrypt SM2公钥加密,密文为C1||C3||C2格式(GM/T 0009),C1为04开头的未压缩点.
+// clearText为明文;publicKey为pem格式的公钥.
+func (ke *LkkEncrypt) Sm2Encrypt(clearText, publicKey []byte) ([]byte, error) {
+	pub, err := parseSm2PublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		k, err := sm2P256.randScalar(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if res := sm2EncryptWithK(pub, clearText, k); res != nil {
+			return res, nil
+		}
+	}
+}
+
+// Sm2Decrypt SM2私钥解密,密文须为C1||C3||C2格式;密文被篡改时返回ErrAuthFailed.
+// cipherText为密文;privateKey为pem格式的私钥.
+func (ke *LkkEncrypt) Sm2Decrypt(cipherText, privateKey []byte) ([]byte, error) {
+	priv, err := parseSm2PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm2Decrypt(priv, cipherText)
+}
+
+// Sm2Sign SM2私钥签名,签名为ASN.1 DER编码的(r,s).
+// data为待签名数据;privateKey为pem格式的私钥;uid为用户身份标识,为空时使用SM2_DEFAULT_UID.
+// 倍点运算为常数时间,但签名中模n的标量运算使用math/big,不能完全抵御计时等侧信道攻击.
+func (ke *LkkEncrypt) Sm2Sign(data, privateKey, uid []byte) ([]byte, error) {
+	priv, err := parseSm2PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uid) == 0 {
+		uid = []byte(SM2_DEFAULT_UID)
+	}
+	r, s, err := sm2Sign(priv, data, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// Sm2Verify SM2公钥验签,验证通过返回nil,签名不匹配返回ErrSignVerify.
+// data为原数据;sign为ASN.1 DER编码的签名;publicKey为pem格式的公钥;uid须与签名时一致.
+func (ke *LkkEncrypt) Sm2Verify(data, sign, publicKey, uid []byte) error {
+	pub, err := parseSm2PublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if len(uid) == 0 {
+		uid = []byte(SM2_DEFAULT_UID)
+	}
+
+	var es ecdsaSignature
+	if rest, err := asn1.Unmarshal(sign, &es); err != nil || len(rest) != 0 || !sm2Verify(pub, data, uid, es.R, es.S) {
+		return ErrSignVerify
+	}
+
+	return nil
+}
@@ -0,0 +1,588 @@
+package kgo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSm3(t *testing.T) {
+	//GB/T 32905 附录A示例
+	if KEncr.Sm3([]byte("abc")) != "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0" {
+		t.Error("Sm3 fail")
+		return
+	}
+	long := []byte(strings.Repeat("abcd", 16))
+	if KEncr.Sm3(long) != "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732" {
+		t.Error("Sm3 fail")
+		return
+	}
+	if KEncr.Sm3(nil) != "1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b" {
+		t.Error("Sm3 fail")
+		return
+	}
+
+	//分段写入与一次写入结果一致
+	h := KEncr.NewSm3()
+	for i := 0; i < len(long); i += 7 {
+		end := i + 7
+		if end > len(long) {
+			end = len(long)
+		}
+		h.Write(long[i:end])
+	}
+	if hex.EncodeToString(h.Sum(nil)) != KEncr.Sm3(long) || h.Size() != 32 || h.BlockSize() != 64 {
+		t.Error("NewSm3 fail")
+		return
+	}
+	h.Reset()
+	h.Write([]byte("abc"))
+	if hex.EncodeToString(h.Sum(nil)) != KEncr.Sm3([]byte("abc")) {
+		t.Error("NewSm3 fail")
+		return
+	}
+}
+
+func BenchmarkSm3(b *testing.B) {
+	b.ResetTimer()
+	str := []byte("hello world")
+	for i := 0; i < b.N; i++ {
+		KEncr.Sm3(str)
+	}
+}
+
+func TestHmacSm3(t *testing.T) {
+	str := []byte("hello world")
+	key := []byte("123456")
+	res := KEncr.HmacSm3(str, key)
+	if len(res) != 64 || res == KEncr.HmacSm3(str, []byte("654321")) {
+		t.Error("HmacSm3 fail")
+		return
+	}
+
+	mac := hmac.New(KEncr.NewSm3, key)
+	mac.Write(str)
+	if hex.EncodeToString(mac.Sum(nil)) != res {
+		t.Error("HmacSm3 fail")
+		return
+	}
+}
+
+func BenchmarkHmacSm3(b *testing.B) {
+	b.ResetTimer()
+	str := []byte("hello world")
+	key := []byte("123456")
+	for i := 0; i < b.N; i++ {
+		KEncr.HmacSm3(str, key)
+	}
+}
+
+func TestSm4Cipher(t *testing.T) {
+	//GB/T 32907 附录A示例
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	block, err := newSm4Cipher(key)
+	if err != nil || block.BlockSize() != 16 {
+		t.Error("newSm4Cipher fail")
+		return
+	}
+
+	buf := make([]byte, 16)
+	block.Encrypt(buf, key)
+	if hex.EncodeToString(buf) != "681edf34d206965e86b3e94f536e4246" {
+		t.Error("Sm4 Encrypt fail")
+		return
+	}
+	block.Decrypt(buf, buf)
+	if !bytes.Equal(buf, key) {
+		t.Error("Sm4 Decrypt fail")
+		return
+	}
+
+	for i := 0; i < 1000000; i++ {
+		block.Encrypt(buf, buf)
+	}
+	if hex.EncodeToString(buf) != "595298c7c6fd271f0402f804c33d3f66" {
+		t.Error("Sm4 Encrypt fail")
+		return
+	}
+
+	_, err = newSm4Cipher([]byte("123"))
+	if err == nil {
+		t.Error("newSm4Cipher fail")
+		return
+	}
+}
+
+func TestSm4ECBEncryptDecrypt(t *testing.T) {
+	key := []byte("1234567890123456")
+	word := []byte("hello world 你好,世界!")
+	for _, pad := range []LkkPKCSType{PKCS_SEVEN, PKCS_ZERO} {
+		enc, err := KEncr.Sm4ECBEncrypt(word, key, pad)
+		if err != nil || len(enc)%16 != 0 {
+			t.Error("Sm4ECBEncrypt fail")
+			return
+		}
+		dec, err := KEncr.Sm4ECBDecrypt(enc, key, pad)
+		if err != nil || !bytes.Equal(dec, word) {
+			t.Error("Sm4ECBDecrypt fail")
+			return
+		}
+	}
+
+	//相同明文分组得到相同密文分组
+	enc, _ := KEncr.Sm4ECBEncrypt(bytes.Repeat([]byte("a"), 32), key, PKCS_NONE)
+	if len(enc) != 32 || !bytes.Equal(enc[:16], enc[16:]) {
+		t.Error("Sm4ECBEncrypt fail")
+		return
+	}
+
+	_, err := KEncr.Sm4ECBEncrypt(word, key, PKCS_NONE)
+	if err == nil {
+		t.Error("Sm4ECBEncrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4ECBEncrypt(word, []byte("123"))
+	if err == nil {
+		t.Error("Sm4ECBEncrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4ECBDecrypt(enc[:20], key)
+	if err == nil {
+		t.Error("Sm4ECBDecrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4ECBDecrypt(enc, []byte("123"))
+	if err == nil {
+		t.Error("Sm4ECBDecrypt fail")
+		return
+	}
+}
+
+func BenchmarkSm4ECBEncrypt(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("1234567890123456")
+	word := []byte("hello world")
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm4ECBEncrypt(word, key)
+	}
+}
+
+func TestSm4CBCEncryptDecrypt(t *testing.T) {
+	key := []byte("1234567890123456")
+	word := []byte("hello world 你好,世界!")
+	enc, err := KEncr.Sm4CBCEncrypt(word, key)
+	if err != nil || len(enc) != 16+32 {
+		t.Error("Sm4CBCEncrypt fail")
+		return
+	}
+	dec, err := KEncr.Sm4CBCDecrypt(enc, key)
+	if err != nil || !bytes.Equal(dec, word) {
+		t.Error("Sm4CBCDecrypt fail")
+		return
+	}
+
+	//随机初始化向量
+	enc2, _ := KEncr.Sm4CBCEncrypt(word, key)
+	if bytes.Equal(enc, enc2) {
+		t.Error("Sm4CBCEncrypt fail")
+		return
+	}
+
+	enc, _ = KEncr.Sm4CBCEncrypt(word, key, PKCS_ZERO)
+	dec, _ = KEncr.Sm4CBCDecrypt(enc, key, PKCS_ZERO)
+	if !bytes.Equal(dec, word) {
+		t.Error("Sm4CBCDecrypt fail")
+		return
+	}
+
+	_, err = KEncr.Sm4CBCDecrypt(enc[:10], key)
+	if err == nil {
+		t.Error("Sm4CBCDecrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4CBCDecrypt(enc[:40], key)
+	if err == nil {
+		t.Error("Sm4CBCDecrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4CBCEncrypt(word, []byte("123"))
+	if err == nil {
+		t.Error("Sm4CBCEncrypt fail")
+		return
+	}
+}
+
+func BenchmarkSm4CBCEncrypt(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("1234567890123456")
+	word := []byte("hello world")
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm4CBCEncrypt(word, key)
+	}
+}
+
+func BenchmarkSm4CBCDecrypt(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("1234567890123456")
+	word := []byte("hello world")
+	enc, _ := KEncr.Sm4CBCEncrypt(word, key)
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm4CBCDecrypt(enc, key)
+	}
+}
+
+func TestSm4CTREncryptDecrypt(t *testing.T) {
+	key := []byte("1234567890123456")
+	word := []byte("hello world 你好,世界!")
+	enc, err := KEncr.Sm4CTREncrypt(word, key)
+	if err != nil || len(enc) != 16+len(word) {
+		t.Error("Sm4CTREncrypt fail")
+		return
+	}
+	dec, err := KEncr.Sm4CTRDecrypt(enc, key)
+	if err != nil || !bytes.Equal(dec, word) {
+		t.Error("Sm4CTRDecrypt fail")
+		return
+	}
+
+	_, err = KEncr.Sm4CTRDecrypt(enc[:10], key)
+	if err == nil {
+		t.Error("Sm4CTRDecrypt fail")
+		return
+	}
+	_, err = KEncr.Sm4CTREncrypt(word, []byte("123"))
+	if err == nil {
+		t.Error("Sm4CTREncrypt fail")
+		return
+	}
+}
+
+func BenchmarkSm4CTREncrypt(b *testing.B) {
+	b.ResetTimer()
+	key := []byte("1234567890123456")
+	word := []byte("hello world")
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm4CTREncrypt(word, key)
+	}
+}
+
+func TestSm2SignWithK(t *testing.T) {
+	//GM/T 0003.5 附录A 签名示例,推荐曲线
+	d := sm2HexInt("3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8")
+	k := sm2HexInt("59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21")
+	priv := newSm2PrivateKey(sm2P256, d)
+	msg := []byte("message digest")
+	uid := []byte(SM2_DEFAULT_UID)
+	e := new(big.Int).SetBytes(sm3Sum(sm2Za(&priv.sm2PublicKey, uid), msg))
+	r, s, err := sm2SignWithK(priv, e, k)
+	if err != nil || r == nil || r.Cmp(sm2HexInt("F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3")) != 0 ||
+		s.Cmp(sm2HexInt("B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")) != 0 {
+		t.Error("sm2SignWithK fail")
+		return
+	}
+	if !sm2Verify(&priv.sm2PublicKey, msg, uid, r, s) || sm2Verify(&priv.sm2PublicKey, msg, []byte("abc"), r, s) {
+		t.Error("sm2Verify fail")
+		return
+	}
+
+	//GB/T 32918.2 附录A 签名示例,Fp-256测试曲线
+	curve := &sm2Curve{
+		P:  sm2HexInt("8542D69E4C044F18E8B92435BF6FF7DE457283915C45517D722EDB8B08F1DFC3"),
+		A:  sm2HexInt("787968B4FA32C3FD2417842E73BBFEFF2F3C848B6831D7E0EC65228B3937E498"),
+		B:  sm2HexInt("63E4C6D3B23B0C849CF84241484BFE48F61D59A5B16BA06E6E12D1DA27C5249A"),
+		N:  sm2HexInt("8542D69E4C044F18E8B92435BF6FF7DD297720630485628D5AE74EE7C32E79B7"),
+		Gx: sm2HexInt("421DEBD61B62EAB6746434EBC3CC315E32220B3BADD50BDC4C4E6C147FEDD43D"),
+		Gy: sm2HexInt("0680512BCBB42C07D47349D2153B70C4E5D7FDFCBFA36EA1A85841B9E46E09A2"),
+	}
+	priv = newSm2PrivateKey(curve, sm2HexInt("128B2FA8BD433C6C068C8D803DFF79792A519A55171B1B650C23661D15897263"))
+	uid = []byte("ALICE123@YAHOO.COM")
+	e = new(big.Int).SetBytes(sm3Sum(sm2Za(&priv.sm2PublicKey, uid), msg))
+	r, s, err = sm2SignWithK(priv, e, sm2HexInt("6CB28D99385C175C94F94E934817663FC176D925DD72B727260DBAAE1FB2F96F"))
+	if err != nil || r == nil || r.Cmp(sm2HexInt("40F1EC59F793D9F49E09DCEF49130D4194F79FB1EED2CAA55BACDB49C4E755D1")) != 0 ||
+		s.Cmp(sm2HexInt("6FC6DAC32C5D5CF10C77DFB20F7C2EB667A457872FB09EC56327A67EC7DEEBE7")) != 0 {
+		t.Error("sm2SignWithK fail")
+		return
+	}
+	if !sm2Verify(&priv.sm2PublicKey, msg, uid, r, s) {
+		t.Error("sm2Verify fail")
+		return
+	}
+}
+
+func TestSm2ScalarMult(t *testing.T) {
+	c := sm2P256
+	x2, y2 := c.add(c.Gx, c.Gy, c.Gx, c.Gy)
+	x3, y3 := c.add(x2, y2, c.Gx, c.Gy)
+	var tests = []struct {
+		k    int64
+		x, y *big.Int
+	}{
+		{1, c.Gx, c.Gy},
+		{2, x2, y2},
+		{3, x3, y3},
+	}
+	for _, test := range tests {
+		x, y := c.scalarMult(c.Gx, c.Gy, big.NewInt(test.k))
+		if x == nil || x.Cmp(test.x) != 0 || y.Cmp(test.y) != 0 {
+			t.Errorf("scalarMult %d fail", test.k)
+			return
+		}
+	}
+
+	//(n-1)G = -G, nG为无穷远点
+	nMinus1 := new(big.Int).Sub(c.N, big.NewInt(1))
+	x, y := c.scalarMult(c.Gx, c.Gy, nMinus1)
+	if x == nil || x.Cmp(c.Gx) != 0 || y.Cmp(new(big.Int).Sub(c.P, c.Gy)) != 0 {
+		t.Error("scalarMult fail")
+		return
+	}
+	if x, _ = c.scalarMult(c.Gx, c.Gy, c.N); x != nil {
+		t.Error("scalarMult fail")
+		return
+	}
+	if x, _ = c.scalarMult(c.Gx, c.Gy, big.NewInt(0)); x != nil {
+		t.Error("scalarMult fail")
+		return
+	}
+
+	//(a+b)G = aG + bG
+	a, _ := c.randScalar(rand.Reader)
+	b, _ := c.randScalar(rand.Reader)
+	xa, ya := c.scalarMult(c.Gx, c.Gy, a)
+	xb, yb := c.scalarMult(c.Gx, c.Gy, b)
+	xs, ys := c.add(xa, ya, xb, yb)
+	ab := new(big.Int).Add(a, b)
+	x, y = c.scalarMult(c.Gx, c.Gy, ab.Mod(ab, c.N))
+	if !c.isOnCurve(xa, ya) || x == nil || x.Cmp(xs) != 0 || y.Cmp(ys) != 0 {
+		t.Error("scalarMult fail")
+		return
+	}
+}
+
+func BenchmarkSm2ScalarMult(b *testing.B) {
+	b.ResetTimer()
+	k := sm2HexInt("59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21")
+	for i := 0; i < b.N; i++ {
+		sm2P256.scalarMult(sm2P256.Gx, sm2P256.Gy, k)
+	}
+}
+
+func TestSm2EncryptWithK(t *testing.T) {
+	//GB/T 32918.4 附录A 加密示例,Fp-256测试曲线;标准中为C1||C2||C3,此处为C1||C3||C2
+	curve := &sm2Curve{
+		P:  sm2HexInt("8542D69E4C044F18E8B92435BF6FF7DE457283915C45517D722EDB8B08F1DFC3"),
+		A:  sm2HexInt("787968B4FA32C3FD2417842E73BBFEFF2F3C848B6831D7E0EC65228B3937E498"),
+		B:  sm2HexInt("63E4C6D3B23B0C849CF84241484BFE48F61D59A5B16BA06E6E12D1DA27C5249A"),
+		N:  sm2HexInt("8542D69E4C044F18E8B92435BF6FF7DD297720630485628D5AE74EE7C32E79B7"),
+		Gx: sm2HexInt("421DEBD61B62EAB6746434EBC3CC315E32220B3BADD50BDC4C4E6C147FEDD43D"),
+		Gy: sm2HexInt("0680512BCBB42C07D47349D2153B70C4E5D7FDFCBFA36EA1A85841B9E46E09A2"),
+	}
+	priv := newSm2PrivateKey(curve, sm2HexInt("1649AB77A00637BD5E2EFE283FBF353534AA7F7CB89463F208DDBC2920BB0DA0"))
+	if priv.X.Cmp(sm2HexInt("435B39CCA8F3B508C1488AFC67BE491A0F7BA07E581A0E4849A5CF70628A7E0A")) != 0 ||
+		priv.Y.Cmp(sm2HexInt("75DDBA78F15FEECB4C7895E2C1CDF5FE01DEBB2CDBADF45399CCF77BBA076A42")) != 0 {
+		t.Error("newSm2PrivateKey fail")
+		return
+	}
+
+	msg := []byte("encryption standard")
+	k := sm2HexInt("4C62EEFD6ECFC2B95B92FD6C3D9575148AFA17425546D49018E5388D49DD7B4F")
+	expected := "04" +
+		"245C26FB68B1DDDDB12C4B6BF9F2B6D5FE60A383B0D18D1C4144ABF17F6252E7" +
+		"76CB9264C2A7E88E52B19903FDC47378F605E36811F5C07423A24B84400F01B8" +
+		"9C3D7360C30156FAB7C80A0276712DA9D8094A634B766D3A285E07480653426D" +
+		"650053A89B41C418B0C3AAD00D886C00286467"
+	res := sm2EncryptWithK(&priv.sm2PublicKey, msg, k)
+	if strings.ToUpper(hex.EncodeToString(res)) != expected {
+		t.Error("sm2EncryptWithK fail")
+		return
+	}
+
+	dec, err := sm2Decrypt(priv, res)
+	if err != nil || !bytes.Equal(dec, msg) {
+		t.Error("sm2Decrypt fail")
+		return
+	}
+}
+
+func TestGenerateSm2KeysEncryptDecrypt(t *testing.T) {
+	word := []byte("hello world 你好,世界!")
+	prikey, pubkey, err := KEncr.GenerateSm2Keys()
+	if err != nil || !bytes.Contains(prikey, []byte("PRIVATE KEY")) || !bytes.Contains(pubkey, []byte("PUBLIC KEY")) {
+		t.Error("GenerateSm2Keys fail")
+		return
+	}
+
+	enc, err := KEncr.Sm2Encrypt(word, pubkey)
+	if err != nil || len(enc) != 65+32+len(word) || enc[0] != 4 {
+		t.Error("Sm2Encrypt fail")
+		return
+	}
+	dec, err := KEncr.Sm2Decrypt(enc, prikey)
+	if err != nil || !bytes.Equal(dec, word) {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+
+	//篡改密文
+	enc[len(enc)-1] ^= 1
+	_, err = KEncr.Sm2Decrypt(enc, prikey)
+	if err != ErrAuthFailed {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+	//C1不在曲线上
+	enc[10] ^= 1
+	_, err = KEncr.Sm2Decrypt(enc, prikey)
+	if err == nil || err == ErrAuthFailed {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+	_, err = KEncr.Sm2Decrypt(enc[:50], prikey)
+	if err == nil {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+
+	//其他密钥
+	prikey2, _, _ := KEncr.GenerateSm2Keys()
+	enc, _ = KEncr.Sm2Encrypt(word, pubkey)
+	_, err = KEncr.Sm2Decrypt(enc, prikey2)
+	if err != ErrAuthFailed {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+
+	ecPri, ecPub, _ := KEncr.GenerateEcdsaKeys(256)
+	_, err = KEncr.Sm2Encrypt(word, ecPub)
+	if err == nil {
+		t.Error("Sm2Encrypt fail")
+		return
+	}
+	_, err = KEncr.Sm2Encrypt(word, []byte("123"))
+	if err == nil {
+		t.Error("Sm2Encrypt fail")
+		return
+	}
+	_, err = KEncr.Sm2Decrypt(enc, ecPri)
+	if err == nil {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+	_, err = KEncr.Sm2Decrypt(enc, []byte("123"))
+	if err == nil {
+		t.Error("Sm2Decrypt fail")
+		return
+	}
+}
+
+func BenchmarkSm2Encrypt(b *testing.B) {
+	b.ResetTimer()
+	word := []byte("hello world")
+	_, pubkey, _ := KEncr.GenerateSm2Keys()
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm2Encrypt(word, pubkey)
+	}
+}
+
+func BenchmarkSm2Decrypt(b *testing.B) {
+	b.ResetTimer()
+	word := []byte("hello world")
+	prikey, pubkey, _ := KEncr.GenerateSm2Keys()
+	enc, _ := KEncr.Sm2Encrypt(word, pubkey)
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm2Decrypt(enc, prikey)
+	}
+}
+
+func TestSm2SignVerify(t *testing.T) {
+	word := []byte("hello world")
+	uid := []byte("kakuilan@163.com")
+	prikey, pubkey, _ := KEncr.GenerateSm2Keys()
+
+	sign, err := KEncr.Sm2Sign(word, prikey, nil)
+	if err != nil {
+		t.Error("Sm2Sign fail")
+		return
+	}
+	if KEncr.Sm2Verify(word, sign, pubkey, nil) != nil || KEncr.Sm2Verify(word, sign, pubkey, []byte(SM2_DEFAULT_UID)) != nil {
+		t.Error("Sm2Verify fail")
+		return
+	}
+	if KEncr.Sm2Verify(word, sign, pubkey, uid) != ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+
+	sign, _ = KEncr.Sm2Sign(word, prikey, uid)
+	if KEncr.Sm2Verify(word, sign, pubkey, uid) != nil {
+		t.Error("Sm2Verify fail")
+		return
+	}
+	if KEncr.Sm2Verify([]byte("tampered"), sign, pubkey, uid) != ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+	if KEncr.Sm2Verify(word, sign[1:], pubkey, uid) != ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+
+	_, pubkey2, _ := KEncr.GenerateSm2Keys()
+	if KEncr.Sm2Verify(word, sign, pubkey2, uid) != ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+
+	ecPri, ecPub, _ := KEncr.GenerateEcdsaKeys(256)
+	_, err = KEncr.Sm2Sign(word, ecPri, nil)
+	if err == nil {
+		t.Error("Sm2Sign fail")
+		return
+	}
+	err = KEncr.Sm2Verify(word, sign, ecPub, uid)
+	if err == nil || err == ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+	err = KEncr.Sm2Verify(word, sign, []byte("123"), uid)
+	if err == nil || err == ErrSignVerify {
+		t.Error("Sm2Verify fail")
+		return
+	}
+
+	//d = n-1时d+1不可逆,须报错而不能无限重试
+	d := new(big.Int).Sub(sm2P256.N, big.NewInt(1))
+	priv := newSm2PrivateKey(sm2P256, d)
+	badPri, _, _ := marshalSm2Keys(priv)
+	if _, err = KEncr.Sm2Sign(word, badPri, nil); err == nil {
+		t.Error("Sm2Sign fail")
+		return
+	}
+	if _, _, err = sm2Sign(priv, word, nil); err == nil {
+		t.Error("Sm2Sign fail")
+		return
+	}
+}
+
+func BenchmarkSm2Sign(b *testing.B) {
+	b.ResetTimer()
+	word := []byte("hello world")
+	prikey, _, _ := KEncr.GenerateSm2Keys()
+	for i := 0; i < b.N; i++ {
+		_, _ = KEncr.Sm2Sign(word, prikey, nil)
+	}
+}
+
+func BenchmarkSm2Verify(b *testing.B) {
+	b.ResetTimer()
+	word := []byte("hello world")
+	prikey, pubkey, _ := KEncr.GenerateSm2Keys()
+	sign, _ := KEncr.Sm2Sign(word, prikey, nil)
+	for i := 0; i < b.N; i++ {
+		_ = KEncr.Sm2Verify(word, sign, pubkey, nil)
+	}
+}
@@ -525,7 +525,7 @@ func (ks *LkkString) Rtrim(str string, characterMask ...string) string {
 
 // Chr 返回相对应于 ascii 所指定的单个字符.
 func (ks *LkkString) Chr(ascii int) string {
-	return string(ascii)
+	return string(rune(ascii))
 }
 
 // Ord 将首字符转换为rune.
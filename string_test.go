@@ -657,6 +657,12 @@ func TestChr(t *testing.T) {
 		t.Error("Chr fail")
 		return
 	}
+
+	//按码点转换
+	if KStr.Chr(0x4e2d) != "中" || KStr.Chr(-1) != "\uFFFD" {
+		t.Error("Chr fail")
+		return
+	}
 }
 
 func BenchmarkChr(b *testing.B) {
@@ -1,8 +1,14 @@
 package kgo
 
 import (
+	"container/list"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -361,3 +367,720 @@ func (kt *LkkTime) IsDate2time(str string) (bool, int64) {
 
 	return true, tim
 }
+
+// Now 当前时间.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker 创建周期定时器,返回其通道和停止函数.
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+// defaultWheel 供KTime.SetTimeout/SetInterval使用的默认时间轮
+var (
+	defaultWheel     *TimingWheel
+	defaultWheelOnce sync.Once
+)
+
+// getDefaultWheel 获取默认时间轮,精度10毫秒.
+func getDefaultWheel() *TimingWheel {
+	defaultWheelOnce.Do(func() {
+		defaultWheel = KTime.NewTimingWheel(10*time.Millisecond, 512)
+	})
+	return defaultWheel
+}
+
+// SetTimeout 使用默认时间轮(精度10毫秒),在d之后执行fn一次.
+func (kt *LkkTime) SetTimeout(d time.Duration, fn CallBack) *TimerHandle {
+	return getDefaultWheel().SetTimeout(d, fn)
+}
+
+// SetInterval 使用默认时间轮(精度10毫秒),每隔d执行fn一次.
+func (kt *LkkTime) SetInterval(d time.Duration, fn CallBack) *TimerHandle {
+	return getDefaultWheel().SetInterval(d, fn)
+}
+
+// NewTimingWheel 创建并启动分层时间轮.tick为精度(最小1毫秒),wheelSize为每层的槽数(默认512).
+// 回调在独立的协程中执行,panic会被恢复.
+func (kt *LkkTime) NewTimingWheel(tick time.Duration, wheelSize int) *TimingWheel {
+	return newTimingWheel(tick, wheelSize, realClock{})
+}
+
+// newTimingWheel 使用指定时钟创建并启动时间轮.
+func newTimingWheel(tick time.Duration, wheelSize int, clock wheelClock) *TimingWheel {
+	if tick < time.Millisecond {
+		tick = time.Millisecond
+	}
+	if wheelSize < 2 {
+		wheelSize = 512
+	}
+
+	tw := &TimingWheel{
+		tick:      tick,
+		wheelSize: int64(wheelSize),
+		startTime: clock.Now(),
+		clock:     clock,
+		stop:      make(chan struct{}),
+		exec: func(fn CallBack) {
+			go func() {
+				defer func() {
+					_ = recover()
+				}()
+				fn()
+			}()
+		},
+	}
+	tw.addLevel()
+
+	tickC, stopTicker := clock.NewTicker(tick)
+	go func() {
+		defer stopTicker()
+		for {
+			select {
+			case <-tickC:
+				tw.advance()
+			case <-tw.stop:
+				return
+			}
+		}
+	}()
+
+	return tw
+}
+
+// addLevel 增加一层时间轮.
+func (tw *TimingWheel) addLevel() {
+	buckets := make([]*list.List, tw.wheelSize)
+	for i := range buckets {
+		buckets[i] = list.New()
+	}
+	tw.levels = append(tw.levels, buckets)
+}
+
+// ticksOf 计算从启动到now+d经过的tick数,向上取整.
+func (tw *TimingWheel) ticksOf(now time.Time, d time.Duration) int64 {
+	elapsed := now.Sub(tw.startTime) + d
+	return int64((elapsed + tw.tick - 1) / tw.tick)
+}
+
+// insert 将任务放入对应层的槽;已到期的返回false.需持有锁.
+func (tw *TimingWheel) insert(th *TimerHandle) bool {
+	delta := th.expire - tw.current
+	if delta <= 0 {
+		return false
+	}
+
+	// span为当前层一圈覆盖的tick数,unit为当前层一个槽的tick数
+	var level int
+	unit, span := int64(1), tw.wheelSize
+	for delta >= span && span <= math.MaxInt64/tw.wheelSize {
+		level++
+		unit, span = span, span*tw.wheelSize
+	}
+	for len(tw.levels) <= level {
+		tw.addLevel()
+	}
+
+	th.bucket = tw.levels[level][(th.expire/unit)%tw.wheelSize]
+	th.elem = th.bucket.PushBack(th)
+	return true
+}
+
+// remove 将任务移出所在的槽.需持有锁.
+func (tw *TimingWheel) remove(th *TimerHandle) {
+	if th.bucket != nil {
+		th.bucket.Remove(th.elem)
+		th.bucket, th.elem = nil, nil
+	}
+}
+
+// advance 推进到时钟的当前时间,并执行到期的任务.
+func (tw *TimingWheel) advance() {
+	//只推进已完整经过的tick,向上取整会使任务提前最多一个tick执行
+	target := int64(tw.clock.Now().Sub(tw.startTime) / tw.tick)
+
+	tw.mu.Lock()
+	var due []CallBack
+	for tw.current < target {
+		tw.current++
+
+		// 从高层到低层,将到达当前槽的任务降级
+		var expired []*TimerHandle
+		unit := int64(1)
+		for level := 1; level < len(tw.levels); level++ {
+			unit *= tw.wheelSize
+			if tw.current%unit != 0 {
+				break
+			}
+			expired = tw.drain(tw.levels[level][(tw.current/unit)%tw.wheelSize], expired)
+		}
+		expired = tw.drain(tw.levels[0][tw.current%tw.wheelSize], expired)
+
+		for _, th := range expired {
+			if th.expire > tw.current {
+				tw.insert(th)
+				continue
+			}
+
+			due = append(due, th.fn)
+			if th.interval > 0 {
+				th.expire += th.interval
+				if th.expire <= tw.current {
+					th.expire = tw.current + 1
+				}
+				tw.insert(th)
+			} else {
+				tw.count--
+			}
+		}
+	}
+	tw.mu.Unlock()
+
+	for _, fn := range due {
+		tw.exec(fn)
+	}
+}
+
+// drain 取出槽中的所有任务.需持有锁.
+func (tw *TimingWheel) drain(bucket *list.List, res []*TimerHandle) []*TimerHandle {
+	for e := bucket.Front(); e != nil; e = bucket.Front() {
+		th := bucket.Remove(e).(*TimerHandle)
+		th.bucket, th.elem = nil, nil
+		res = append(res, th)
+	}
+	return res
+}
+
+// schedule 添加定时任务.
+func (tw *TimingWheel) schedule(d, interval time.Duration, fn CallBack) *TimerHandle {
+	th := &TimerHandle{tw: tw, fn: fn}
+	if interval > 0 {
+		th.interval = int64((interval + tw.tick - 1) / tw.tick)
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	th.expire = tw.ticksOf(tw.clock.Now(), d)
+	if th.expire <= tw.current {
+		th.expire = tw.current + 1
+	}
+	tw.insert(th)
+	tw.count++
+
+	return th
+}
+
+// SetTimeout 在d之后执行fn一次.
+func (tw *TimingWheel) SetTimeout(d time.Duration, fn CallBack) *TimerHandle {
+	return tw.schedule(d, 0, fn)
+}
+
+// SetInterval 每隔d执行fn一次,直到被取消.
+func (tw *TimingWheel) SetInterval(d time.Duration, fn CallBack) *TimerHandle {
+	if d < tw.tick {
+		d = tw.tick
+	}
+	return tw.schedule(d, d, fn)
+}
+
+// Len 获取待执行的任务数.
+func (tw *TimingWheel) Len() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.count
+}
+
+// Stop 停止时间轮,未执行的任务将不再执行.
+func (tw *TimingWheel) Stop() {
+	tw.stopOnce.Do(func() {
+		close(tw.stop)
+	})
+}
+
+// Cancel 取消定时任务;任务已执行(一次性任务)或已取消时返回false.
+func (th *TimerHandle) Cancel() bool {
+	tw := th.tw
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if th.canceled || th.bucket == nil {
+		return false
+	}
+
+	tw.remove(th)
+	th.canceled = true
+	tw.count--
+	return true
+}
+
+// cronMacros cron预定义的宏
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronMonthNames 月份名称
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// cronDowNames 星期名称
+var cronDowNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// parseCronValue 解析单个值,可以是数字或名称.
+func parseCronValue(str string, min, max int, names map[string]int) (int, error) {
+	if val, ok := names[strings.ToUpper(str)]; ok {
+		return val, nil
+	}
+
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", str)
+	} else if val < min || val > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", val, min, max)
+	}
+	return val, nil
+}
+
+// parseCronField 解析字段中的常规部分(*、a、a-b、及其/n步长,逗号分隔),返回位图.
+func parseCronField(field string, min, max int, names map[string]int) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		expr, step := part, 1
+		pos := strings.Index(part, "/")
+		if pos >= 0 {
+			expr = part[:pos]
+			if step, err = strconv.Atoi(part[pos+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		start, end := min, max
+		if expr != "*" && expr != "?" {
+			bounds := strings.SplitN(expr, "-", 2)
+			if start, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return 0, err
+			}
+			if len(bounds) == 2 {
+				if end, err = parseCronValue(bounds[1], min, max, names); err != nil {
+					return 0, err
+				}
+			} else if pos < 0 {
+				//无步长时为单个值,N/step表示从N到最大值
+				end = start
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return
+}
+
+// parseCronDom 解析日字段,支持L、L-n、LW、nW.
+func parseCronDom(cs *CronSchedule, field string) error {
+	var regular []string
+	for _, part := range strings.Split(field, ",") {
+		upper := strings.ToUpper(part)
+		switch {
+		case upper == "L":
+			cs.domLast = append(cs.domLast, 0)
+		case upper == "LW":
+			cs.domLastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			offset, err := strconv.Atoi(upper[2:])
+			if err != nil || offset < 0 || offset > 30 {
+				return fmt.Errorf("invalid day-of-month %q", part)
+			}
+			cs.domLast = append(cs.domLast, offset)
+		case strings.HasSuffix(upper, "W"):
+			day, err := parseCronValue(upper[:len(upper)-1], 1, 31, nil)
+			if err != nil {
+				return err
+			}
+			cs.domWeekday = append(cs.domWeekday, day)
+		default:
+			regular = append(regular, part)
+		}
+	}
+
+	if len(regular) > 0 {
+		bits, err := parseCronField(strings.Join(regular, ","), 1, 31, nil)
+		if err != nil {
+			return err
+		}
+		cs.dom = bits
+	}
+	return nil
+}
+
+// parseCronDow 解析星期字段,支持nL、n#k;7同0,表示星期日.
+func parseCronDow(cs *CronSchedule, field string) error {
+	var regular []string
+	for _, part := range strings.Split(field, ",") {
+		upper := strings.ToUpper(part)
+		if pos := strings.Index(upper, "#"); pos > 0 {
+			wd, err := parseCronValue(upper[:pos], 0, 7, cronDowNames)
+			if err != nil {
+				return err
+			}
+			nth, err := strconv.Atoi(upper[pos+1:])
+			if err != nil || nth < 1 || nth > 5 {
+				return fmt.Errorf("invalid day-of-week %q", part)
+			}
+			cs.dowNth = append(cs.dowNth, [2]int{wd % 7, nth})
+		} else if len(upper) > 1 && strings.HasSuffix(upper, "L") {
+			wd, err := parseCronValue(upper[:len(upper)-1], 0, 7, cronDowNames)
+			if err != nil {
+				return err
+			}
+			cs.dowLast |= 1 << uint(wd%7)
+		} else {
+			regular = append(regular, part)
+		}
+	}
+
+	if len(regular) > 0 {
+		bits, err := parseCronField(strings.Join(regular, ","), 0, 7, cronDowNames)
+		if err != nil {
+			return err
+		}
+		if bits&(1<<7) != 0 {
+			bits |= 1
+		}
+		cs.dow = bits &^ (1 << 7)
+	}
+	return nil
+}
+
+// ParseCron 解析cron表达式.支持:
+// 5字段(分 时 日 月 星期)和6字段(秒 分 时 日 月 星期);
+// 宏@yearly、@annually、@monthly、@weekly、@daily、@midnight、@hourly及@every 5m;
+// 范围a-b、步长*/n、列表a,b,月份和星期名称;日字段的L、L-n、LW、nW,星期字段的nL、n#k;
+// 以"TZ=时区 "或"CRON_TZ=时区 "开头指定时区.
+func (kt *LkkTime) ParseCron(spec string) (*CronSchedule, error) {
+	cs := &CronSchedule{}
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		pos := strings.Index(spec, " ")
+		if pos < 0 {
+			return nil, fmt.Errorf("[ParseCron] %q: missing fields after time zone", spec)
+		}
+		loc, err := time.LoadLocation(spec[strings.Index(spec, "=")+1 : pos])
+		if err != nil {
+			return nil, fmt.Errorf("[ParseCron] %q: %s", spec, err.Error())
+		}
+		cs.loc = loc
+		spec = strings.TrimSpace(spec[pos:])
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[7:]))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("[ParseCron] %q: invalid duration, at least 1s", spec)
+		}
+		cs.every = d
+		return cs, nil
+	} else if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	} else if len(fields) != 6 {
+		return nil, fmt.Errorf("[ParseCron] %q: expected 5 or 6 fields, got %d", spec, len(fields))
+	}
+
+	var err error
+	if cs.second, err = parseCronField(fields[0], 0, 59, nil); err == nil {
+		if cs.minute, err = parseCronField(fields[1], 0, 59, nil); err == nil {
+			if cs.hour, err = parseCronField(fields[2], 0, 23, nil); err == nil {
+				if err = parseCronDom(cs, fields[3]); err == nil {
+					if cs.month, err = parseCronField(fields[4], 1, 12, cronMonthNames); err == nil {
+						err = parseCronDow(cs, fields[5])
+					}
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[ParseCron] %q: %s", spec, err.Error())
+	}
+	cs.domStar = fields[3] == "*" || fields[3] == "?"
+	cs.dowStar = fields[5] == "*" || fields[5] == "?"
+
+	return cs, nil
+}
+
+// cronNearestWeekday 获取距当月day日最近的工作日(不跨月).
+func cronNearestWeekday(year int, month time.Month, day, lastDay int, loc *time.Location) int {
+	if day > lastDay {
+		day = lastDay
+	}
+	switch time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// dayMatches 检查日期是否匹配日和星期字段;两者都有限制时满足其一即可.
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	day, wd := t.Day(), int(t.Weekday())
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+
+	domMatch := cs.dom&(1<<uint(day)) != 0
+	for _, offset := range cs.domLast {
+		domMatch = domMatch || day == lastDay-offset
+	}
+	if cs.domLastWeekday {
+		domMatch = domMatch || day == cronNearestWeekday(t.Year(), t.Month(), lastDay, lastDay, t.Location())
+	}
+	for _, n := range cs.domWeekday {
+		domMatch = domMatch || day == cronNearestWeekday(t.Year(), t.Month(), n, lastDay, t.Location())
+	}
+
+	dowMatch := cs.dow&(1<<uint(wd)) != 0 || (cs.dowLast&(1<<uint(wd)) != 0 && day+7 > lastDay)
+	for _, nth := range cs.dowNth {
+		dowMatch = dowMatch || (wd == nth[0] && (day-1)/7+1 == nth[1])
+	}
+
+	if cs.domStar && cs.dowStar {
+		return true
+	} else if cs.domStar {
+		return dowMatch
+	} else if cs.dowStar {
+		return domMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next 获取after之后的下一次执行时间;5年内没有匹配的时间时返回零值.
+func (cs *CronSchedule) Next(after time.Time) time.Time {
+	if cs.every > 0 {
+		return after.Add(cs.every - time.Duration(after.Nanosecond()))
+	}
+
+	loc := after.Location()
+	if cs.loc != nil {
+		loc = cs.loc
+	}
+	t := after.In(loc).Add(time.Second - time.Duration(after.Nanosecond()))
+	yearLimit := t.Year() + 5
+	added := false
+
+WRAP:
+	for t.Year() <= yearLimit {
+		for cs.month&(1<<uint(t.Month())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue WRAP
+			}
+		}
+
+		for !cs.dayMatches(t) {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 0, 1)
+			if t.Day() == 1 {
+				continue WRAP
+			}
+		}
+
+		for cs.hour&(1<<uint(t.Hour())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+			}
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue WRAP
+			}
+		}
+
+		for cs.minute&(1<<uint(t.Minute())) == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Minute)
+			}
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue WRAP
+			}
+		}
+
+		for cs.second&(1<<uint(t.Second())) == 0 {
+			added = true
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue WRAP
+			}
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// NextN 获取after之后的n次执行时间.
+func (cs *CronSchedule) NextN(after time.Time, n int) []time.Time {
+	res := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		after = cs.Next(after)
+		if after.IsZero() {
+			break
+		}
+		res = append(res, after)
+	}
+	return res
+}
+
+// NewCronRunner 创建并启动定时任务执行器,精度为1秒.
+func (kt *LkkTime) NewCronRunner() *CronRunner {
+	return newCronRunner(realClock{})
+}
+
+// newCronRunner 使用指定时钟创建并启动定时任务执行器.
+func newCronRunner(clock wheelClock) *CronRunner {
+	cr := &CronRunner{
+		clock:   clock,
+		entries: make(map[int]*cronEntry),
+		stop:    make(chan struct{}),
+	}
+
+	// 调度协程也计入wg,保证Stop时不再有新任务加入
+	cr.wg.Add(1)
+	tickC, stopTicker := clock.NewTicker(time.Second)
+	go func() {
+		defer cr.wg.Done()
+		defer stopTicker()
+		for {
+			select {
+			case <-tickC:
+				cr.runDue()
+			case <-cr.stop:
+				return
+			}
+		}
+	}()
+
+	return cr
+}
+
+// runDue 执行到期的任务;上次执行尚未结束的任务本次跳过.
+func (cr *CronRunner) runDue() {
+	now := cr.clock.Now()
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	for _, entry := range cr.entries {
+		if entry.next.IsZero() || entry.next.After(now) {
+			continue
+		}
+		entry.next = entry.schedule.Next(now)
+
+		if !atomic.CompareAndSwapInt32(&entry.running, 0, 1) {
+			continue
+		}
+		cr.wg.Add(1)
+		go cr.run(entry, cr.onPanic)
+	}
+}
+
+// run 执行任务并恢复panic.
+func (cr *CronRunner) run(entry *cronEntry, onPanic func(id int, err interface{})) {
+	defer func() {
+		if r := recover(); r != nil && onPanic != nil {
+			onPanic(entry.id, r)
+		}
+		atomic.StoreInt32(&entry.running, 0)
+		cr.wg.Done()
+	}()
+	entry.fn()
+}
+
+// AddJob 添加定时任务,spec为cron表达式(见ParseCron),返回任务ID.
+func (cr *CronRunner) AddJob(spec string, fn CallBack) (int, error) {
+	schedule, err := KTime.ParseCron(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.nextID++
+	cr.entries[cr.nextID] = &cronEntry{
+		id:       cr.nextID,
+		schedule: schedule,
+		fn:       fn,
+		next:     schedule.Next(cr.clock.Now()),
+	}
+
+	return cr.nextID, nil
+}
+
+// RemoveJob 移除定时任务,正在执行的不受影响.
+func (cr *CronRunner) RemoveJob(id int) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if _, ok := cr.entries[id]; !ok {
+		return false
+	}
+	delete(cr.entries, id)
+	return true
+}
+
+// NextRun 获取任务的下一次执行时间;任务不存在时返回零值.
+func (cr *CronRunner) NextRun(id int) time.Time {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if entry, ok := cr.entries[id]; ok {
+		return entry.next
+	}
+	return time.Time{}
+}
+
+// SetPanicHandler 设置任务panic时的处理函数,默认忽略.
+func (cr *CronRunner) SetPanicHandler(fn func(id int, err interface{})) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.onPanic = fn
+}
+
+// Stop 停止执行器,并等待正在执行的任务结束.
+func (cr *CronRunner) Stop() {
+	cr.stopOnce.Do(func() {
+		close(cr.stop)
+	})
+	cr.wg.Wait()
+}
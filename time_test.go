@@ -2,6 +2,8 @@ package kgo
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -484,3 +486,364 @@ func BenchmarkIsDate2time(b *testing.B) {
 		_, _ = KTime.IsDate2time("1990-01-02 03:14:59")
 	}
 }
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+	ch  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), ch: make(chan time.Time)}
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+func (fc *fakeClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	return fc.ch, func() {}
+}
+
+// Advance 推进时钟.通道无缓冲,第二次发送完成即说明第一次的tick已处理完.
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	fc.now = fc.now.Add(d)
+	now := fc.now
+	fc.mu.Unlock()
+	fc.ch <- now
+	fc.ch <- now
+}
+
+func newTestWheel(tick time.Duration, size int) (*TimingWheel, *fakeClock) {
+	fc := newFakeClock()
+	tw := newTimingWheel(tick, size, fc)
+	tw.exec = func(fn CallBack) {
+		fn()
+	}
+	return tw, fc
+}
+
+func TestTimingWheelSetTimeout(t *testing.T) {
+	tw, fc := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+
+	var fired []int
+	tw.SetTimeout(5*time.Millisecond, func() {
+		fired = append(fired, 5)
+	})
+	//跨越多层
+	tw.SetTimeout(100*time.Millisecond, func() {
+		fired = append(fired, 100)
+	})
+	tw.SetTimeout(1000*time.Millisecond, func() {
+		fired = append(fired, 1000)
+	})
+	if tw.Len() != 3 {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+
+	fc.Advance(4 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+	fc.Advance(time.Millisecond)
+	if len(fired) != 1 || fired[0] != 5 {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+
+	fc.Advance(94 * time.Millisecond)
+	if len(fired) != 1 {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+	//一次推进多个tick
+	fc.Advance(901 * time.Millisecond)
+	if fmt.Sprint(fired) != "[5 100 1000]" || tw.Len() != 0 {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+
+	//在tick中间添加的任务不能提前执行
+	tw2, fc2 := newTestWheel(10*time.Millisecond, 8)
+	defer tw2.Stop()
+	fc2.Advance(5 * time.Millisecond)
+	var done bool
+	tw2.SetTimeout(10*time.Millisecond, func() {
+		done = true
+	})
+	fc2.Advance(6 * time.Millisecond)
+	if done {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+	fc2.Advance(9 * time.Millisecond)
+	if !done {
+		t.Error("TimingWheel SetTimeout fail")
+		return
+	}
+}
+
+func TestTimingWheelSetInterval(t *testing.T) {
+	tw, fc := newTestWheel(10*time.Millisecond, 4)
+	defer tw.Stop()
+
+	var num int
+	th := tw.SetInterval(30*time.Millisecond, func() {
+		num++
+	})
+	for i := 0; i < 30; i++ {
+		fc.Advance(10 * time.Millisecond)
+	}
+	if num != 10 || tw.Len() != 1 {
+		t.Error("TimingWheel SetInterval fail")
+		return
+	}
+
+	if !th.Cancel() || th.Cancel() || tw.Len() != 0 {
+		t.Error("TimingWheel SetInterval fail")
+		return
+	}
+	fc.Advance(time.Second)
+	if num != 10 {
+		t.Error("TimingWheel SetInterval fail")
+		return
+	}
+}
+
+func TestTimingWheelCancel(t *testing.T) {
+	tw, fc := newTestWheel(time.Millisecond, 16)
+	defer tw.Stop()
+
+	var fired int
+	handles := make([]*TimerHandle, 0, 100000)
+	for i := 1; i <= 100000; i++ {
+		handles = append(handles, tw.SetTimeout(time.Duration(i%5000+1)*time.Millisecond, func() {
+			fired++
+		}))
+	}
+	for i := 0; i < len(handles); i += 2 {
+		handles[i].Cancel()
+	}
+	if tw.Len() != 50000 {
+		t.Error("TimingWheel Cancel fail")
+		return
+	}
+
+	fc.Advance(5 * time.Second)
+	if fired != 50000 || tw.Len() != 0 || handles[1].Cancel() {
+		t.Error("TimingWheel Cancel fail")
+		return
+	}
+}
+
+func TestTimingWheelReal(t *testing.T) {
+	done := make(chan struct{})
+	start := time.Now()
+	KTime.SetTimeout(30*time.Millisecond, func() {
+		close(done)
+	})
+
+	select {
+	case <-done:
+		if time.Since(start) < 30*time.Millisecond {
+			t.Error("SetTimeout fail")
+			return
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("SetTimeout fail")
+		return
+	}
+
+	var num int32
+	th := KTime.SetInterval(10*time.Millisecond, func() {
+		atomic.AddInt32(&num, 1)
+	})
+	time.Sleep(100 * time.Millisecond)
+	th.Cancel()
+	if atomic.LoadInt32(&num) == 0 {
+		t.Error("SetInterval fail")
+		return
+	}
+}
+
+func BenchmarkTimingWheelSetTimeout(b *testing.B) {
+	tw := KTime.NewTimingWheel(time.Millisecond, 512)
+	defer tw.Stop()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tw.SetTimeout(time.Minute, func() {}).Cancel()
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		nexts []string
+	}{
+		{"*/15 * * * *", "2020-03-10 23:04:35", []string{"2020-03-10 23:15:00", "2020-03-10 23:30:00", "2020-03-10 23:45:00"}},
+		{"5/1 * * * *", "2020-03-10 23:57:00", []string{"2020-03-10 23:58:00", "2020-03-10 23:59:00", "2020-03-11 00:05:00"}},
+		{"5/15 * * * *", "2020-03-10 23:04:35", []string{"2020-03-10 23:05:00", "2020-03-10 23:20:00", "2020-03-10 23:35:00", "2020-03-10 23:50:00", "2020-03-11 00:05:00"}},
+		{"30 */20 9-10 * * *", "2020-03-10 09:59:00", []string{"2020-03-10 10:00:30", "2020-03-10 10:20:30", "2020-03-10 10:40:30"}},
+		{"0 12 1,15 * *", "2020-03-10 23:04:35", []string{"2020-03-15 12:00:00", "2020-04-01 12:00:00", "2020-04-15 12:00:00"}},
+		{"0 0 * JAN-MAR MON-FRI", "2020-03-27 01:00:00", []string{"2020-03-30 00:00:00", "2020-03-31 00:00:00", "2021-01-01 00:00:00"}},
+		{"0 0 * * 7", "2020-03-10 00:00:00", []string{"2020-03-15 00:00:00", "2020-03-22 00:00:00"}},
+		{"@daily", "2020-03-10 23:04:35", []string{"2020-03-11 00:00:00", "2020-03-12 00:00:00"}},
+		{"@yearly", "2020-03-10 23:04:35", []string{"2021-01-01 00:00:00"}},
+		{"@weekly", "2020-03-10 23:04:35", []string{"2020-03-15 00:00:00"}},
+		{"0 0 29 2 *", "2020-03-10 00:00:00", []string{"2024-02-29 00:00:00"}},
+		{"0 0 L * *", "2020-01-10 00:00:00", []string{"2020-01-31 00:00:00", "2020-02-29 00:00:00", "2020-03-31 00:00:00"}},
+		{"0 0 L-1 * *", "2020-02-10 00:00:00", []string{"2020-02-28 00:00:00", "2020-03-30 00:00:00"}},
+		{"0 0 LW * *", "2020-05-01 00:00:00", []string{"2020-05-29 00:00:00", "2020-06-30 00:00:00"}},
+		{"0 0 15W * *", "2020-02-01 00:00:00", []string{"2020-02-14 00:00:00", "2020-03-16 00:00:00", "2020-04-15 00:00:00"}},
+		{"0 0 1W * *", "2020-02-01 00:00:00", []string{"2020-02-03 00:00:00", "2020-03-02 00:00:00"}},
+		{"0 0 * * 5L", "2020-03-01 00:00:00", []string{"2020-03-27 00:00:00", "2020-04-24 00:00:00"}},
+		{"0 0 * * FRI#3", "2020-03-01 00:00:00", []string{"2020-03-20 00:00:00", "2020-04-17 00:00:00"}},
+		{"0 0 13 * 5", "2020-03-01 00:00:00", []string{"2020-03-06 00:00:00", "2020-03-13 00:00:00", "2020-03-20 00:00:00"}},
+		{"0 0 13 * ?", "2020-03-01 00:00:00", []string{"2020-03-13 00:00:00", "2020-04-13 00:00:00"}},
+		{"@every 90m", "2020-03-10 23:04:35", []string{"2020-03-11 00:34:35", "2020-03-11 02:04:35"}},
+	}
+	for _, test := range tests {
+		cs, err := KTime.ParseCron(test.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) fail: %v", test.spec, err)
+			return
+		}
+
+		after, _ := time.ParseInLocation("2006-01-02 15:04:05", test.after, time.UTC)
+		var actual []string
+		for _, next := range cs.NextN(after, len(test.nexts)) {
+			actual = append(actual, next.Format("2006-01-02 15:04:05"))
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(test.nexts) {
+			t.Errorf("ParseCron(%q) expected %v, got %v", test.spec, test.nexts, actual)
+			return
+		}
+	}
+}
+
+func TestParseCronTimeZone(t *testing.T) {
+	cs, err := KTime.ParseCron("CRON_TZ=Asia/Shanghai 0 9 * * *")
+	if err != nil {
+		t.Error("ParseCron fail")
+		return
+	}
+
+	after := time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC)
+	next := cs.Next(after)
+	if !next.Equal(time.Date(2020, 3, 10, 1, 0, 0, 0, time.UTC)) || next.Location().String() != "Asia/Shanghai" {
+		t.Error("ParseCron time zone fail")
+		return
+	}
+
+	_, err = KTime.ParseCron("TZ=Nowhere/City * * * * *")
+	if err == nil {
+		t.Error("ParseCron time zone fail")
+		return
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	specs := []string{"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * 32W * *", "* * * * 1#6", "@every 1ms", "@every x", "@never", "a * * * *"}
+	for _, spec := range specs {
+		if _, err := KTime.ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) should fail", spec)
+			return
+		}
+	}
+}
+
+func BenchmarkParseCron(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = KTime.ParseCron("0 */5 9-18 * * MON-FRI")
+	}
+}
+
+func BenchmarkCronNext(b *testing.B) {
+	cs, _ := KTime.ParseCron("0 0 * * FRI#3")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cs.Next(myDate)
+	}
+}
+
+func TestCronRunner(t *testing.T) {
+	fc := newFakeClock()
+	cr := newCronRunner(fc)
+	defer cr.Stop()
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	id, err := cr.AddJob("* * * * * *", func() {
+		started <- struct{}{}
+		<-release
+	})
+	if err != nil || !cr.NextRun(id).Equal(time.Unix(1, 0)) {
+		t.Error("CronRunner AddJob fail")
+		return
+	}
+
+	_, err = cr.AddJob("* * *", nil)
+	if err == nil {
+		t.Error("CronRunner AddJob fail")
+		return
+	}
+
+	fc.Advance(time.Second)
+	<-started
+
+	//上次执行未结束,跳过
+	fc.Advance(time.Second)
+	fc.Advance(time.Second)
+	if len(started) != 0 || !cr.NextRun(id).Equal(time.Unix(4, 0)) {
+		t.Error("CronRunner overlap fail")
+		return
+	}
+
+	//任务结束后恢复执行
+	release <- struct{}{}
+	for restarted := false; !restarted; {
+		fc.Advance(time.Second)
+		select {
+		case <-started:
+			restarted = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	release <- struct{}{}
+
+	if !cr.RemoveJob(id) || cr.RemoveJob(id) || !cr.NextRun(id).IsZero() {
+		t.Error("CronRunner RemoveJob fail")
+		return
+	}
+}
+
+func TestCronRunnerPanic(t *testing.T) {
+	fc := newFakeClock()
+	cr := newCronRunner(fc)
+
+	panics := make(chan interface{}, 1)
+	cr.SetPanicHandler(func(id int, err interface{}) {
+		panics <- err
+	})
+	_, _ = cr.AddJob("@every 2s", func() {
+		panic("cron panic")
+	})
+
+	fc.Advance(time.Second)
+	fc.Advance(time.Second)
+	if <-panics != "cron panic" {
+		t.Error("CronRunner panic fail")
+		return
+	}
+	cr.Stop()
+}